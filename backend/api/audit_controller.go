@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"react-go-admin-backend/services"
+	"react-go-admin-backend/utils"
+)
+
+// AuditController 审计日志控制器
+type AuditController struct {
+	auditService *services.AuditService
+	loginService *services.LoginService
+}
+
+// NewAuditController 创建审计日志控制器
+func NewAuditController() *AuditController {
+	return &AuditController{
+		auditService: &services.AuditService{},
+		loginService: &services.LoginService{},
+	}
+}
+
+// GetList 获取审计日志列表，支持按操作人、操作、资源类型、资源 ID 及时间范围过滤
+func (ctrl *AuditController) GetList(c *gin.Context) {
+	listQuery, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+	query := services.AuditLogQuery{
+		ListQuery:    listQuery,
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+	}
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		query.UserID = uint(id)
+	}
+	if v := c.Query("resourceId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		query.ResourceID = uint(id)
+	}
+
+	logs, total, err := ctrl.auditService.GetAuditLogList(query)
+	if err != nil {
+		respondError(c, err, "get_audit_logs_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(logs, total, query.Page, query.PageSize)))
+}
+
+// GetLoginLogs 获取登录历史，支持按用户、IP、结果、原因及时间范围过滤
+func (ctrl *AuditController) GetLoginLogs(c *gin.Context) {
+	listQuery, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+	query := services.LoginLogQuery{
+		ListQuery: listQuery,
+		IP:        c.Query("ip"),
+		Reason:    c.Query("reason"),
+	}
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		query.UserID = uint(id)
+	}
+	if v := c.Query("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		query.Success = &success
+	}
+
+	logs, total, err := ctrl.loginService.GetLoginLogList(query)
+	if err != nil {
+		respondError(c, err, "get_login_logs_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(logs, total, query.Page, query.PageSize)))
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	"react-go-admin-backend/models"
 	"react-go-admin-backend/services"
 	"react-go-admin-backend/utils"
 
@@ -11,13 +12,23 @@ import (
 
 // AuthController 认证控制器
 type AuthController struct {
-	userService *services.UserService
+	userService       *services.UserService
+	permissionService *services.PermissionService
+	tokenService      *services.TokenService
+	loginService      *services.LoginService
+	resetService      *services.PasswordResetService
+	mfaService        *services.MFAService
 }
 
 // NewAuthController 创建认证控制器
 func NewAuthController() *AuthController {
 	return &AuthController{
-		userService: &services.UserService{},
+		userService:       &services.UserService{},
+		permissionService: &services.PermissionService{},
+		tokenService:      &services.TokenService{},
+		loginService:      &services.LoginService{},
+		resetService:      &services.PasswordResetService{},
+		mfaService:        &services.MFAService{},
 	}
 }
 
@@ -31,32 +42,89 @@ type LoginRequest struct {
 func (ctrl *AuthController) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
-	// 查询用户
-	user, err := ctrl.userService.GetUserByUsername(req.Username)
+	// 校验凭据，同时记录登录历史并进行防暴力破解检查
+	user, err := ctrl.loginService.Authenticate(services.LoginAttempt{
+		Username:  req.Username,
+		Password:  req.Password,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("用户名或密码错误"))
+		respondError(c, err, "login_failed")
 		return
 	}
 
-	// 验证密码
-	if !ctrl.userService.VerifyPassword(user, req.Password) {
-		c.JSON(http.StatusOK, utils.Error("用户名或密码错误"))
+	// 已启用两步验证时，先返回短期挑战令牌，验证码校验通过后再签发令牌
+	if user.MFAEnabled {
+		mfaToken, expiresIn, err := ctrl.mfaService.CreateChallenge(user.ID)
+		if err != nil {
+			respondError(c, err, "login_failed")
+			return
+		}
+		c.JSON(http.StatusOK, utils.Success(gin.H{
+			"mfaRequired": true,
+			"mfaToken":    mfaToken,
+			"expiresIn":   expiresIn,
+		}))
 		return
 	}
 
+	ctrl.respondLogin(c, user)
+}
+
+// VerifyMFARequest 登录第二步验证请求
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP 验证码或恢复码
+}
+
+// VerifyMFA 登录第二步：校验两步验证码并签发令牌
+func (ctrl *AuthController) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user, err := ctrl.loginService.VerifyMFA(services.LoginAttempt{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}, req.MFAToken, req.Code)
+	if err != nil {
+		respondError(c, err, "login_failed")
+		return
+	}
+
+	ctrl.respondLogin(c, user)
+}
+
+// respondLogin 签发令牌并返回登录结果
+func (ctrl *AuthController) respondLogin(c *gin.Context, user *models.User) {
+	mfaSetupRequired := false
+	if !user.MFAEnabled {
+		required, err := ctrl.mfaService.Required(user.ID)
+		if err != nil {
+			respondError(c, err, "login_failed")
+			return
+		}
+		mfaSetupRequired = required
+	}
+
 	// 生成 token
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	pair, err := ctrl.tokenService.IssueTokenPair(user)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("生成 token 失败"))
+		respondError(c, err, "issue_token_failed")
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.Success(gin.H{
-		"token": token,
+		"token":        pair.Token,
+		"refreshToken": pair.RefreshToken,
+		"expiresIn":    pair.ExpiresIn,
 		"user": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
@@ -64,12 +132,55 @@ func (ctrl *AuthController) Login(c *gin.Context) {
 			"email":    user.Email,
 			"phone":    user.Phone,
 			"avatar":   user.Avatar,
+			"locale":   user.Locale,
 		},
+		"mustChangePassword": user.MustChangePassword,
+		"mfaSetupRequired":   mfaSetupRequired,
 	}))
 }
 
-// Logout 用户登出
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+func (ctrl *AuthController) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	pair, err := ctrl.tokenService.Refresh(req.RefreshToken)
+	if err != nil {
+		respondError(c, err, "issue_token_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(pair))
+}
+
+// LogoutRequest 登出请求
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Logout 用户登出，吊销当前访问令牌及对应的刷新令牌
 func (ctrl *AuthController) Logout(c *gin.Context) {
+	var req LogoutRequest
+	_ = c.ShouldBindJSON(&req)
+
+	claims, _ := c.Get("claims")
+	if err := ctrl.tokenService.RevokeAccessToken(claims.(*utils.Claims)); err != nil {
+		respondError(c, err, "logout_failed")
+		return
+	}
+
+	if req.RefreshToken != "" {
+		ctrl.tokenService.RevokeRefreshToken(req.RefreshToken)
+	}
+
 	c.JSON(http.StatusOK, utils.Success(nil))
 }
 
@@ -79,21 +190,68 @@ func (ctrl *AuthController) GetProfile(c *gin.Context) {
 
 	user, err := ctrl.userService.GetUserByID(userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("获取用户信息失败"))
+		respondError(c, err, "get_profile_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{
+		"id":                   user.ID,
+		"username":             user.Username,
+		"realname":             user.Realname,
+		"email":                user.Email,
+		"phone":                user.Phone,
+		"avatar":               user.Avatar,
+		"status":               user.Status,
+		"must_change_password": user.MustChangePassword,
+		"mfa_enabled":          user.MFAEnabled,
+		"locale":               user.Locale,
+	}))
+}
+
+// GetPermissions 获取当前用户的权限代码和菜单树
+func (ctrl *AuthController) GetPermissions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	permissions, err := ctrl.permissionService.GetUserPermissions(userID.(uint))
+	if err != nil {
+		respondError(c, err, "get_user_permissions_failed")
 		return
 	}
 
+	codes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		codes = append(codes, permission.Code)
+	}
+
 	c.JSON(http.StatusOK, utils.Success(gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"realname": user.Realname,
-		"email":    user.Email,
-		"phone":    user.Phone,
-		"avatar":   user.Avatar,
-		"status":   user.Status,
+		"codes": codes,
+		"menus": ctrl.permissionService.GetUserMenuTree(permissions),
 	}))
 }
 
+// PreferencesRequest 个人偏好设置请求
+type PreferencesRequest struct {
+	Locale string `json:"locale" binding:"omitempty,locale"` // 界面语言，为空时按 Accept-Language
+}
+
+// UpdatePreferences 更新当前用户的偏好设置
+func (ctrl *AuthController) UpdatePreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := ctrl.userService.UpdateUser(userID.(uint), userID.(uint), map[string]interface{}{"locale": req.Locale}); err != nil {
+		respondError(c, err, "update_preferences_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{"locale": req.Locale}))
+}
+
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"oldPassword" binding:"required"`
@@ -104,31 +262,148 @@ type ChangePasswordRequest struct {
 func (ctrl *AuthController) ChangePassword(c *gin.Context) {
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
+	// 校验旧密码及密码策略后更新密码
 	userID, _ := c.Get("user_id")
+	if err := ctrl.userService.ChangePassword(userID.(uint), req.OldPassword, req.NewPassword); err != nil {
+		respondError(c, err, "change_password_failed")
+		return
+	}
+
+	// 修改密码会使旧令牌失效，为当前会话签发新令牌
 	user, err := ctrl.userService.GetUserByID(userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("获取用户信息失败"))
+		respondError(c, err, "get_profile_failed")
+		return
+	}
+	pair, err := ctrl.tokenService.IssueTokenPair(user)
+	if err != nil {
+		respondError(c, err, "issue_token_failed")
 		return
 	}
 
-	// 验证旧密码
-	if !ctrl.userService.VerifyPassword(user, req.OldPassword) {
-		c.JSON(http.StatusOK, utils.Error("旧密码错误"))
+	c.JSON(http.StatusOK, utils.Success(pair))
+}
+
+// ForgotPasswordRequest 申请重置密码请求
+type ForgotPasswordRequest struct {
+	Account string `json:"account" binding:"required"` // 用户名或邮箱
+}
+
+// ForgotPassword 申请自助重置密码，无论账号是否存在均返回成功
+func (ctrl *AuthController) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
 		return
 	}
 
-	// 更新密码
-	updates := map[string]interface{}{
-		"password": req.NewPassword,
+	if err := ctrl.resetService.RequestReset(req.Account); err != nil {
+		respondError(c, err, "forgot_password_failed")
+		return
 	}
-	if err := ctrl.userService.UpdateUser(user.ID, updates); err != nil {
-		c.JSON(http.StatusOK, utils.Error("修改密码失败"))
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// ResetPasswordRequest 使用重置令牌设置新密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (ctrl *AuthController) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := ctrl.resetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		respondError(c, err, "reset_password_failed")
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.Success(nil))
 }
+
+// SetupMFA 生成两步验证密钥及配置二维码，需调用 EnableMFA 确认后生效
+func (ctrl *AuthController) SetupMFA(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	setup, err := ctrl.mfaService.Setup(userID.(uint))
+	if err != nil {
+		respondError(c, err, "mfa_setup_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(setup))
+}
+
+// MFACodeRequest 两步验证码请求
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// EnableMFA 校验验证码后启用两步验证，返回恢复码
+func (ctrl *AuthController) EnableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	codes, err := ctrl.mfaService.Enable(userID.(uint), req.Code)
+	if err != nil {
+		respondError(c, err, "mfa_enable_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{"recoveryCodes": codes}))
+}
+
+// DisableMFARequest 关闭两步验证请求
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// DisableMFA 关闭两步验证
+func (ctrl *AuthController) DisableMFA(c *gin.Context) {
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := ctrl.mfaService.Disable(userID.(uint), req.Password, req.Code); err != nil {
+		respondError(c, err, "mfa_disable_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// RegenerateRecoveryCodes 重新生成两步验证恢复码
+func (ctrl *AuthController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	codes, err := ctrl.mfaService.RegenerateRecoveryCodes(userID.(uint), req.Code)
+	if err != nil {
+		respondError(c, err, "mfa_recovery_codes_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{"recoveryCodes": codes}))
+}
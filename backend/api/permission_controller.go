@@ -0,0 +1,177 @@
+package api
+
+import (
+	"net/http"
+
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/services"
+	"react-go-admin-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionController 权限控制器
+type PermissionController struct {
+	permissionService *services.PermissionService
+}
+
+// NewPermissionController 创建权限控制器
+func NewPermissionController() *PermissionController {
+	return &PermissionController{
+		permissionService: &services.PermissionService{},
+	}
+}
+
+// GetList 获取权限列表
+func (ctrl *PermissionController) GetList(c *gin.Context) {
+	query, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+
+	permissions, total, err := ctrl.permissionService.GetPermissionList(query)
+	if err != nil {
+		respondError(c, err, "get_permission_list_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(permissions, total, query.Page, query.PageSize)))
+}
+
+// GetTree 获取权限树
+func (ctrl *PermissionController) GetTree(c *gin.Context) {
+	tree, err := ctrl.permissionService.GetPermissionTree()
+	if err != nil {
+		respondError(c, err, "get_permission_tree_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(tree))
+}
+
+// GetDetail 获取权限详情
+func (ctrl *PermissionController) GetDetail(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	permission, err := ctrl.permissionService.GetPermissionByID(id)
+	if err != nil {
+		respondError(c, err, "get_permission_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(permission))
+}
+
+// CreatePermissionRequest 创建权限请求
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	ParentCode  string `json:"parent_code"`
+	Path        string `json:"path"`
+	Type        int    `json:"type" binding:"omitempty,oneof=1 2 3"`
+	Sort        int    `json:"sort"`
+	Description string `json:"description"`
+}
+
+// Create 创建权限
+func (ctrl *PermissionController) Create(c *gin.Context) {
+	var req CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	permission := &models.Permission{
+		Name:        req.Name,
+		Code:        req.Code,
+		ParentCode:  req.ParentCode,
+		Path:        req.Path,
+		Type:        req.Type,
+		Sort:        req.Sort,
+		Description: req.Description,
+	}
+	if permission.Type == 0 {
+		permission.Type = 1
+	}
+
+	if err := ctrl.permissionService.CreatePermission(permission); err != nil {
+		respondError(c, err, "create_permission_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(permission))
+}
+
+// UpdatePermissionRequest 更新权限请求
+type UpdatePermissionRequest struct {
+	Name        string  `json:"name"`
+	Code        string  `json:"code"`
+	ParentCode  *string `json:"parent_code"`
+	Path        *string `json:"path"`
+	Type        *int    `json:"type" binding:"omitempty,oneof=1 2 3"`
+	Sort        *int    `json:"sort"`
+	Description string  `json:"description"`
+}
+
+// Update 更新权限
+func (ctrl *PermissionController) Update(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Code != "" {
+		updates["code"] = req.Code
+	}
+	if req.ParentCode != nil {
+		updates["parent_code"] = *req.ParentCode
+	}
+	if req.Path != nil {
+		updates["path"] = *req.Path
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Sort != nil {
+		updates["sort"] = *req.Sort
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+
+	if err := ctrl.permissionService.UpdatePermission(id, updates); err != nil {
+		respondError(c, err, "update_permission_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// Delete 删除权限，权限仍分配给角色时需传入 force=true
+func (ctrl *PermissionController) Delete(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.permissionService.DeletePermission(id, c.Query("force") == "true"); err != nil {
+		respondError(c, err, "delete_permission_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"react-go-admin-backend/services"
+	"react-go-admin-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindPathID 解析路径中名为 name 的 ID 参数，无法解析为正整数时返回 400 并返回 false
+func bindPathID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		msg := tr(c, "invalid_id", name)
+		c.JSON(http.StatusBadRequest, utils.ErrorWithFields(msg, []utils.FieldError{
+			{Field: name, Rule: "id", Message: msg},
+		}))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseListQuery 解析通用列表查询参数
+func parseListQuery(c *gin.Context) (services.ListQuery, error) {
+	query := services.ListQuery{
+		Keyword:   c.Query("keyword"),
+		SortBy:    c.Query("sortBy"),
+		SortOrder: c.Query("sortOrder"),
+	}
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.PageSize, _ = strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	status, err := parseStatus(c.Query("status"))
+	if err != nil {
+		return query, err
+	}
+	query.Status = status
+
+	if query.CreatedFrom, err = parseDate(c.Query("createdFrom"), false); err != nil {
+		return query, err
+	}
+	if query.CreatedTo, err = parseDate(c.Query("createdTo"), true); err != nil {
+		return query, err
+	}
+
+	query.Normalize()
+	return query, nil
+}
+
+// parseStatus 解析状态参数，支持 1/0 和 active/inactive
+func parseStatus(value string) (*int, error) {
+	var status int
+	switch strings.ToLower(value) {
+	case "":
+		return nil, nil
+	case "1", "active":
+		status = 1
+	case "0", "inactive":
+		status = 0
+	default:
+		return nil, errors.New("无效的状态")
+	}
+	return &status, nil
+}
+
+// parseDate 解析日期参数，支持 2006-01-02 和 RFC3339；endOfDay 为 true 时纯日期取次日零点作为开区间上界
+func parseDate(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, errors.New("无效的日期")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
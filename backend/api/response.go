@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"react-go-admin-backend/i18n"
+	"react-go-admin-backend/middleware"
+	"react-go-admin-backend/services"
+	"react-go-admin-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tr 按当前请求语言翻译消息
+func tr(c *gin.Context, key string, args ...interface{}) string {
+	return i18n.T(middleware.GetLocale(c), key, args...)
+}
+
+// respondError 将业务错误转换为对应的 HTTP 状态码及响应；
+// 未知错误记录日志后返回 500 及 fallbackKey 对应的提示，避免泄露数据库等内部错误信息
+func respondError(c *gin.Context, err error, fallbackKey string) {
+	status, resp := localizeError(c, err, fallbackKey)
+	c.JSON(status, resp)
+}
+
+// localizeError 按错误类型确定 HTTP 状态码，并按请求语言生成响应
+func localizeError(c *gin.Context, err error, fallbackKey string) (int, utils.Response) {
+	var (
+		validation *services.ValidationError
+		notFound   *services.NotFoundError
+		conflict   *services.ConflictError
+		forbidden  *services.ForbiddenError
+		auth       *services.UnauthorizedError
+		locked     *services.LockedError
+		limited    *services.RateLimitError
+	)
+	switch {
+	case errors.As(err, &validation):
+		// 校验错误可能是共享的哨兵错误，复制后再翻译
+		fields := make([]utils.FieldError, len(validation.Fields))
+		messages := make([]string, 0, len(fields))
+		for i, field := range validation.Fields {
+			if field.Key != "" {
+				field.Message = tr(c, field.Key, field.Args...)
+			}
+			fields[i] = field
+			messages = append(messages, field.Message)
+		}
+		return http.StatusBadRequest, utils.ErrorWithFields(strings.Join(messages, "; "), fields)
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, utils.Fail(http.StatusNotFound, notFound.Key, tr(c, notFound.Key, notFound.Args...))
+	case errors.As(err, &conflict):
+		resp := utils.Fail(http.StatusConflict, conflict.Key, tr(c, conflict.Key, conflict.Args...))
+		if conflict.Details != nil {
+			resp.Data = conflict.Details
+		}
+		return http.StatusConflict, resp
+	case errors.As(err, &forbidden):
+		return http.StatusForbidden, utils.Fail(http.StatusForbidden, forbidden.Key, tr(c, forbidden.Key, forbidden.Args...))
+	case errors.As(err, &auth):
+		return http.StatusUnauthorized, utils.Fail(http.StatusUnauthorized, auth.Key, tr(c, auth.Key, auth.Args...))
+	case errors.As(err, &locked):
+		return http.StatusLocked, utils.Fail(http.StatusLocked, locked.Key, tr(c, locked.Key, locked.Args...))
+	case errors.As(err, &limited):
+		return http.StatusTooManyRequests, utils.Fail(http.StatusTooManyRequests, limited.Key, tr(c, limited.Key, limited.Args...))
+	default:
+		log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+		return http.StatusInternalServerError, utils.Fail(http.StatusInternalServerError, utils.ErrKeyInternal, tr(c, fallbackKey))
+	}
+}
+
+// respondBadRequest 请求参数无法解析时返回 400
+func respondBadRequest(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, utils.BadRequest(tr(c, utils.ErrKeyBadRequest)))
+}
@@ -24,25 +24,31 @@ func NewRoleController() *RoleController {
 
 // GetList 获取角色列表
 func (ctrl *RoleController) GetList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	query, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
 
-	roles, total, err := ctrl.roleService.GetRoleList(page, pageSize)
+	roles, total, err := ctrl.roleService.GetRoleList(query)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("获取角色列表失败"))
+		respondError(c, err, "get_role_list_failed")
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(roles, total, page, pageSize)))
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(roles, total, query.Page, query.PageSize)))
 }
 
 // GetDetail 获取角色详情
 func (ctrl *RoleController) GetDetail(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
-	role, err := ctrl.roleService.GetRoleByID(uint(id))
+	role, err := ctrl.roleService.GetRoleByID(id)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		respondError(c, err, "get_role_failed")
 		return
 	}
 
@@ -52,15 +58,17 @@ func (ctrl *RoleController) GetDetail(c *gin.Context) {
 // CreateRoleRequest 创建角色请求
 type CreateRoleRequest struct {
 	Name        string `json:"name" binding:"required"`
-	Code        string `json:"code" binding:"required"`
+	Code        string `json:"code" binding:"required,role_code"`
 	Description string `json:"description"`
+	Status      *int   `json:"status" binding:"omitempty,oneof=0 1"`
+	RequireMFA  *bool  `json:"require_mfa"` // 是否要求该角色用户启用两步验证
 }
 
 // Create 创建角色
 func (ctrl *RoleController) Create(c *gin.Context) {
 	var req CreateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
@@ -68,10 +76,17 @@ func (ctrl *RoleController) Create(c *gin.Context) {
 		Name:        req.Name,
 		Code:        req.Code,
 		Description: req.Description,
+		Status:      1,
+	}
+	if req.Status != nil {
+		role.Status = *req.Status
+	}
+	if req.RequireMFA != nil {
+		role.RequireMFA = *req.RequireMFA
 	}
 
 	if err := ctrl.roleService.CreateRole(role); err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		respondError(c, err, "create_role_failed")
 		return
 	}
 
@@ -81,17 +96,22 @@ func (ctrl *RoleController) Create(c *gin.Context) {
 // UpdateRoleRequest 更新角色请求
 type UpdateRoleRequest struct {
 	Name        string `json:"name"`
-	Code        string `json:"code"`
+	Code        string `json:"code" binding:"omitempty,role_code"`
 	Description string `json:"description"`
+	Status      *int   `json:"status" binding:"omitempty,oneof=0 1"`
+	RequireMFA  *bool  `json:"require_mfa"` // 是否要求该角色用户启用两步验证
 }
 
 // Update 更新角色
 func (ctrl *RoleController) Update(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
 	var req UpdateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
@@ -105,21 +125,121 @@ func (ctrl *RoleController) Update(c *gin.Context) {
 	if req.Description != "" {
 		updates["description"] = req.Description
 	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+	if req.RequireMFA != nil {
+		updates["require_mfa"] = *req.RequireMFA
+	}
 
-	if err := ctrl.roleService.UpdateRole(uint(id), updates); err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+	if err := ctrl.roleService.UpdateRole(id, updates); err != nil {
+		respondError(c, err, "update_role_failed")
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.Success(nil))
 }
 
-// Delete 删除角色
+// Delete 删除角色，角色仍被用户使用时需传入 force=true 及 replacementRoleId
 func (ctrl *RoleController) Delete(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	opts := services.DeleteRoleOptions{Force: c.Query("force") == "true"}
+	if v := c.Query("replacementRoleId"); v != "" {
+		replacementID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		opts.ReplacementRoleID = uint(replacementID)
+	}
+
+	if err := ctrl.roleService.DeleteRole(id, opts); err != nil {
+		respondError(c, err, "delete_role_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// AssignPermissionsRequest 分配角色权限请求
+type AssignPermissionsRequest struct {
+	PermissionIDs   []uint   `json:"permission_ids"`
+	PermissionCodes []string `json:"permission_codes"`
+	IncludeParents  bool     `json:"include_parents"`
+}
+
+// AssignPermissions 分配角色权限
+func (ctrl *RoleController) AssignPermissions(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AssignPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := ctrl.roleService.AssignPermissions(id, req.PermissionIDs, req.PermissionCodes, req.IncludeParents); err != nil {
+		respondError(c, err, "assign_role_permissions_failed")
+		return
+	}
+
+	role, err := ctrl.roleService.GetRoleByID(id)
+	if err != nil {
+		respondError(c, err, "assign_role_permissions_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(role))
+}
+
+// GetTrash 获取回收站中的角色
+func (ctrl *RoleController) GetTrash(c *gin.Context) {
+	query, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+
+	roles, total, err := ctrl.roleService.GetDeletedRoleList(query)
+	if err != nil {
+		respondError(c, err, "get_trash_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(roles, total, query.Page, query.PageSize)))
+}
+
+// Restore 从回收站恢复角色
+func (ctrl *RoleController) Restore(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.roleService.RestoreRole(id); err != nil {
+		respondError(c, err, "restore_role_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// Purge 彻底删除回收站中的角色
+func (ctrl *RoleController) Purge(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
-	if err := ctrl.roleService.DeleteRole(uint(id)); err != nil {
-		c.JSON(http.StatusOK, utils.Error("删除角色失败"))
+	if err := ctrl.roleService.PurgeRole(id); err != nil {
+		respondError(c, err, "purge_role_failed")
 		return
 	}
 
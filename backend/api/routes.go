@@ -8,44 +8,92 @@ import (
 
 // RegisterRoutes 注册路由
 func RegisterRoutes(r *gin.Engine) {
+	setupValidator()
+
 	// API 路由组
 	api := r.Group("/api")
+	api.Use(middleware.Locale())
 
 	// 认证路由（无需 token）
 	authCtrl := NewAuthController()
 	auth := api.Group("/auth")
 	{
 		auth.POST("/login", authCtrl.Login)
-		auth.POST("/logout", authCtrl.Logout)
+		auth.POST("/refresh", authCtrl.Refresh)
+		auth.POST("/forgot-password", authCtrl.ForgotPassword)
+		auth.POST("/reset-password", middleware.AuditPasswordReset("reset_password_by_token"), authCtrl.ResetPassword)
+		auth.POST("/logout", middleware.AuthMiddleware(), authCtrl.Logout)
 		auth.GET("/profile", middleware.AuthMiddleware(), authCtrl.GetProfile)
-		auth.POST("/change-password", middleware.AuthMiddleware(), authCtrl.ChangePassword)
+		auth.GET("/permissions", middleware.AuthMiddleware(), authCtrl.GetPermissions)
+		auth.POST("/change-password", middleware.AuthMiddleware(), middleware.AuditSelf("change_password"), authCtrl.ChangePassword)
+		auth.PUT("/preferences", middleware.AuthMiddleware(), middleware.AuditSelf("update_preferences"), authCtrl.UpdatePreferences)
+		auth.POST("/mfa/verify", authCtrl.VerifyMFA)
+		auth.POST("/mfa/setup", middleware.AuthMiddleware(), authCtrl.SetupMFA)
+		auth.POST("/mfa/enable", middleware.AuthMiddleware(), middleware.AuditSelf("enable_mfa"), authCtrl.EnableMFA)
+		auth.POST("/mfa/disable", middleware.AuthMiddleware(), middleware.AuditSelf("disable_mfa"), authCtrl.DisableMFA)
+		auth.POST("/mfa/recovery-codes", middleware.AuthMiddleware(), authCtrl.RegenerateRecoveryCodes)
 	}
 
 	// 需要认证的路由
 	authorized := api.Group("")
-	authorized.Use(middleware.AuthMiddleware())
+	authorized.Use(middleware.AuthMiddleware(), middleware.PasswordChangeGuard(), middleware.MFASetupGuard())
 	{
 		// 用户管理
 		userCtrl := NewUserController()
 		users := authorized.Group("/users")
 		{
-			users.GET("", userCtrl.GetList)
-			users.GET("/:id", userCtrl.GetDetail)
-			users.POST("", userCtrl.Create)
-			users.PUT("/:id", userCtrl.Update)
-			users.DELETE("/:id", userCtrl.Delete)
+			users.GET("", middleware.RequirePermission("system:user:view"), userCtrl.GetList)
+			users.GET("/trash", middleware.RequirePermission("system:user:view"), userCtrl.GetTrash)
+			users.GET("/:id", middleware.RequirePermission("system:user:view"), userCtrl.GetDetail)
+			users.POST("", middleware.RequirePermission("system:user:add"), middleware.Audit("user", "create"), userCtrl.Create)
+			users.POST("/batch-delete", middleware.RequirePermission("system:user:delete"), middleware.AuditBatch("user", "batch_delete", "userIds"), userCtrl.BatchDelete)
+			users.POST("/batch-status", middleware.RequirePermission("system:user:edit"), middleware.AuditBatch("user", "batch_status", "userIds"), userCtrl.BatchUpdateStatus)
+			users.POST("/batch-roles", middleware.RequirePermission("system:user:edit"), middleware.AuditBatch("user", "batch_roles", "userIds"), userCtrl.BatchAssignRoles)
+			users.POST("/batch-reset-password", middleware.RequirePermission("system:user:edit"), middleware.AuditBatch("user", "batch_reset_password", "userIds"), userCtrl.BatchResetPassword)
+			users.PUT("/:id", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "update"), userCtrl.Update)
+			users.DELETE("/:id", middleware.RequirePermission("system:user:delete"), middleware.Audit("user", "delete"), userCtrl.Delete)
+			users.POST("/:id/restore", middleware.RequirePermission("system:user:delete"), middleware.Audit("user", "restore"), userCtrl.Restore)
+			users.DELETE("/:id/purge", middleware.RequirePermission("system:user:purge"), middleware.Audit("user", "purge"), userCtrl.Purge)
+			users.PUT("/:id/roles", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "assign_roles"), userCtrl.AssignRoles)
+			users.POST("/:id/roles", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "add_roles"), userCtrl.AddRoles)
+			users.DELETE("/:id/roles/:roleId", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "remove_role"), userCtrl.RemoveRole)
+			users.DELETE("/:id/sessions", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "revoke_sessions"), userCtrl.RevokeSessions)
+			users.POST("/:id/reset-password", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "reset_password"), userCtrl.ResetPassword)
+			users.DELETE("/:id/mfa", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "reset_mfa"), userCtrl.ResetMFA)
+			users.POST("/:id/unlock", middleware.RequirePermission("system:user:edit"), middleware.Audit("user", "unlock"), userCtrl.Unlock)
 		}
 
 		// 角色管理
 		roleCtrl := NewRoleController()
 		roles := authorized.Group("/roles")
 		{
-			roles.GET("", roleCtrl.GetList)
-			roles.GET("/:id", roleCtrl.GetDetail)
-			roles.POST("", roleCtrl.Create)
-			roles.PUT("/:id", roleCtrl.Update)
-			roles.DELETE("/:id", roleCtrl.Delete)
+			roles.GET("", middleware.RequirePermission("system:role:view"), roleCtrl.GetList)
+			roles.GET("/trash", middleware.RequirePermission("system:role:view"), roleCtrl.GetTrash)
+			roles.GET("/:id", middleware.RequirePermission("system:role:view"), roleCtrl.GetDetail)
+			roles.POST("", middleware.RequirePermission("system:role:add"), middleware.Audit("role", "create"), roleCtrl.Create)
+			roles.PUT("/:id", middleware.RequirePermission("system:role:edit"), middleware.Audit("role", "update"), roleCtrl.Update)
+			roles.DELETE("/:id", middleware.RequirePermission("system:role:delete"), middleware.Audit("role", "delete"), roleCtrl.Delete)
+			roles.POST("/:id/restore", middleware.RequirePermission("system:role:delete"), middleware.Audit("role", "restore"), roleCtrl.Restore)
+			roles.DELETE("/:id/purge", middleware.RequirePermission("system:role:purge"), middleware.Audit("role", "purge"), roleCtrl.Purge)
+			roles.PUT("/:id/permissions", middleware.RequirePermission("system:permission:assign"), middleware.Audit("role", "assign_permissions"), roleCtrl.AssignPermissions)
 		}
 
+		// 权限管理
+		permissionCtrl := NewPermissionController()
+		permissions := authorized.Group("/permissions")
+		{
+			permissions.GET("", middleware.RequirePermission("system:permission:view"), permissionCtrl.GetList)
+			permissions.GET("/tree", middleware.RequirePermission("system:permission:view"), permissionCtrl.GetTree)
+			permissions.GET("/:id", middleware.RequirePermission("system:permission:view"), permissionCtrl.GetDetail)
+			permissions.POST("", middleware.RequirePermission("system:permission:add"), middleware.Audit("permission", "create"), permissionCtrl.Create)
+			permissions.PUT("/:id", middleware.RequirePermission("system:permission:edit"), middleware.Audit("permission", "update"), permissionCtrl.Update)
+			permissions.DELETE("/:id", middleware.RequirePermission("system:permission:delete"), middleware.Audit("permission", "delete"), permissionCtrl.Delete)
+		}
+
+		// 审计日志与登录历史
+		auditCtrl := NewAuditController()
+		authorized.GET("/audit-logs", middleware.RequirePermission("system:audit:view"), auditCtrl.GetList)
+		authorized.GET("/login-logs", middleware.RequirePermission("system:audit:login"), auditCtrl.GetLoginLogs)
+
 	}
 }
@@ -13,37 +13,58 @@ import (
 
 // UserController 用户控制器
 type UserController struct {
-	userService *services.UserService
+	userService  *services.UserService
+	tokenService *services.TokenService
+	loginService *services.LoginService
+	mfaService   *services.MFAService
 }
 
 // NewUserController 创建用户控制器
 func NewUserController() *UserController {
 	return &UserController{
-		userService: &services.UserService{},
+		userService:  &services.UserService{},
+		tokenService: &services.TokenService{},
+		loginService: &services.LoginService{},
+		mfaService:   &services.MFAService{},
 	}
 }
 
 // GetList 获取用户列表
 func (ctrl *UserController) GetList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	listQuery, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+	query := services.UserListQuery{ListQuery: listQuery}
+	if roleID := c.Query("roleId"); roleID != "" {
+		id, err := strconv.ParseUint(roleID, 10, 32)
+		if err != nil {
+			respondBadRequest(c)
+			return
+		}
+		query.RoleID = uint(id)
+	}
 
-	users, total, err := ctrl.userService.GetUserList(page, pageSize)
+	users, total, err := ctrl.userService.GetUserList(query)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error("获取用户列表失败"))
+		respondError(c, err, "get_user_list_failed")
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(users, total, page, pageSize)))
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(users, total, query.Page, query.PageSize)))
 }
 
 // GetDetail 获取用户详情
 func (ctrl *UserController) GetDetail(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
-	user, err := ctrl.userService.GetUserByID(uint(id))
+	user, err := ctrl.userService.GetUserByID(id)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		respondError(c, err, "get_user_failed")
 		return
 	}
 
@@ -52,19 +73,20 @@ func (ctrl *UserController) GetDetail(c *gin.Context) {
 
 // CreateUserRequest 创建用户请求
 type CreateUserRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,username"`
 	Password string `json:"password" binding:"required"`
 	Realname string `json:"realname" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Phone    string `json:"phone"`
+	Phone    string `json:"phone" binding:"omitempty,phone"`
 	Avatar   string `json:"avatar"`
+	RoleIDs  []uint `json:"role_ids"`
 }
 
 // Create 创建用户
 func (ctrl *UserController) Create(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
@@ -77,9 +99,12 @@ func (ctrl *UserController) Create(c *gin.Context) {
 		Avatar:   req.Avatar,
 		Status:   1,
 	}
+	for _, roleID := range req.RoleIDs {
+		user.Roles = append(user.Roles, models.Role{ID: roleID})
+	}
 
 	if err := ctrl.userService.CreateUser(user); err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		respondError(c, err, "create_user_failed")
 		return
 	}
 
@@ -88,21 +113,25 @@ func (ctrl *UserController) Create(c *gin.Context) {
 
 // UpdateUserRequest 更新用户请求
 type UpdateUserRequest struct {
-	Username string `json:"username"`
+	Username string `json:"username" binding:"omitempty,username"`
 	Realname string `json:"realname"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Phone    string `json:"phone" binding:"omitempty,phone"`
 	Avatar   string `json:"avatar"`
-	Status   *int   `json:"status"`
+	Status   *int   `json:"status" binding:"omitempty,oneof=0 1"`
+	RoleIDs  []uint `json:"role_ids"`
 }
 
 // Update 更新用户
 func (ctrl *UserController) Update(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.Error("参数错误"))
+		respondBindError(c, err)
 		return
 	}
 
@@ -126,8 +155,10 @@ func (ctrl *UserController) Update(c *gin.Context) {
 		updates["status"] = *req.Status
 	}
 
-	if err := ctrl.userService.UpdateUser(uint(id), updates); err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+	// 无字段更新时同样校验用户存在；role_ids 为 null 时不修改角色，为 [] 时清空角色
+	operatorID, _ := c.Get("user_id")
+	if err := ctrl.userService.UpdateUserWithRoles(operatorID.(uint), id, updates, req.RoleIDs); err != nil {
+		respondError(c, err, "update_user_failed")
 		return
 	}
 
@@ -136,10 +167,302 @@ func (ctrl *UserController) Update(c *gin.Context) {
 
 // Delete 删除用户
 func (ctrl *UserController) Delete(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	if err := ctrl.userService.DeleteUser(operatorID.(uint), id); err != nil {
+		respondError(c, err, "delete_user_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// UserRolesRequest 用户角色请求
+type UserRolesRequest struct {
+	RoleIDs []uint `json:"role_ids"`
+}
+
+// AssignRoles 设置用户角色（整体替换）
+func (ctrl *UserController) AssignRoles(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	if err := ctrl.userService.AssignRoles(operatorID.(uint), id, req.RoleIDs); err != nil {
+		respondError(c, err, "assign_user_roles_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// AddRoles 为用户追加角色
+func (ctrl *UserController) AddRoles(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := ctrl.userService.AddRoles(id, req.RoleIDs); err != nil {
+		respondError(c, err, "add_user_roles_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// RemoveRole 移除用户角色
+func (ctrl *UserController) RemoveRole(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+	roleID, ok := bindPathID(c, "roleId")
+	if !ok {
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	if err := ctrl.userService.RemoveRole(operatorID.(uint), id, roleID); err != nil {
+		respondError(c, err, "remove_user_role_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// RevokeSessions 注销用户的全部会话
+func (ctrl *UserController) RevokeSessions(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := ctrl.userService.GetUserByID(id); err != nil {
+		respondError(c, err, "revoke_sessions_failed")
+		return
+	}
+
+	if err := ctrl.tokenService.RevokeUserSessions(id); err != nil {
+		respondError(c, err, "revoke_sessions_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// BatchRequest 批量操作请求
+type BatchRequest struct {
+	UserIDs []uint `json:"userIds" binding:"required,min=1"`
+}
+
+// BatchStatusRequest 批量修改状态请求
+type BatchStatusRequest struct {
+	UserIDs []uint `json:"userIds" binding:"required,min=1"`
+	Status  *int   `json:"status" binding:"required,oneof=0 1"`
+}
+
+// BatchRolesRequest 批量分配角色请求
+type BatchRolesRequest struct {
+	UserIDs []uint `json:"userIds" binding:"required,min=1"`
+	RoleIDs []uint `json:"roleIds"`
+	Mode    string `json:"mode" binding:"omitempty,oneof=replace add remove"`
+}
+
+// BatchResetPasswordRequest 批量重置密码请求
+type BatchResetPasswordRequest struct {
+	UserIDs  []uint `json:"userIds" binding:"required,min=1"`
+	Password string `json:"password"`
+}
+
+// BatchDelete 批量删除用户
+func (ctrl *UserController) BatchDelete(c *gin.Context) {
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	results, err := ctrl.userService.BatchDelete(operatorID.(uint), req.UserIDs)
+	respondBatch(c, results, err)
+}
+
+// BatchUpdateStatus 批量启用或禁用用户
+func (ctrl *UserController) BatchUpdateStatus(c *gin.Context) {
+	var req BatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	results, err := ctrl.userService.BatchUpdateStatus(operatorID.(uint), req.UserIDs, *req.Status)
+	respondBatch(c, results, err)
+}
+
+// BatchAssignRoles 批量分配角色
+func (ctrl *UserController) BatchAssignRoles(c *gin.Context) {
+	var req BatchRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = services.BatchRoleReplace
+	}
+
+	operatorID, _ := c.Get("user_id")
+	results, err := ctrl.userService.BatchAssignRoles(operatorID.(uint), req.UserIDs, req.RoleIDs, req.Mode)
+	respondBatch(c, results, err)
+}
+
+// BatchResetPassword 批量重置密码
+func (ctrl *UserController) BatchResetPassword(c *gin.Context) {
+	var req BatchResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	results, err := ctrl.userService.BatchResetPassword(operatorID.(uint), req.UserIDs, req.Password)
+	respondBatch(c, results, err)
+}
+
+// respondBatch 输出批量操作结果
+func respondBatch(c *gin.Context, results []services.BatchResult, err error) {
+	if err != nil {
+		respondError(c, err, "batch_failed")
+		return
+	}
+
+	successCount := 0
+	for i, result := range results {
+		if result.Success {
+			successCount++
+			continue
+		}
+		if result.Err != nil {
+			_, resp := localizeError(c, result.Err, "process_failed")
+			results[i].ErrorKey = resp.Error
+			results[i].Error = resp.Msg
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{
+		"results":      results,
+		"successCount": successCount,
+		"failureCount": len(results) - successCount,
+	}))
+}
+
+// ResetPassword 重置用户密码为系统生成的临时密码，用户下次登录时必须修改
+func (ctrl *UserController) ResetPassword(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+	password, err := ctrl.userService.ResetPassword(operatorID.(uint), id)
+	if err != nil {
+		respondError(c, err, "reset_password_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(gin.H{"password": password}))
+}
+
+// ResetMFA 重置用户的两步验证，用户需重新绑定
+func (ctrl *UserController) ResetMFA(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.mfaService.Reset(id); err != nil {
+		respondError(c, err, "mfa_reset_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// Unlock 解除因登录失败过多导致的账号锁定
+func (ctrl *UserController) Unlock(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.loginService.UnlockUser(id); err != nil {
+		respondError(c, err, "unlock_user_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// GetTrash 获取回收站中的用户
+func (ctrl *UserController) GetTrash(c *gin.Context) {
+	query, err := parseListQuery(c)
+	if err != nil {
+		respondBadRequest(c)
+		return
+	}
+
+	users, total, err := ctrl.userService.GetDeletedUserList(query)
+	if err != nil {
+		respondError(c, err, "get_trash_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(utils.NewPageData(users, total, query.Page, query.PageSize)))
+}
+
+// Restore 从回收站恢复用户
+func (ctrl *UserController) Restore(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.userService.RestoreUser(id); err != nil {
+		respondError(c, err, "restore_user_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Success(nil))
+}
+
+// Purge 彻底删除回收站中的用户
+func (ctrl *UserController) Purge(c *gin.Context) {
+	id, ok := bindPathID(c, "id")
+	if !ok {
+		return
+	}
 
-	if err := ctrl.userService.DeleteUser(uint(id)); err != nil {
-		c.JSON(http.StatusOK, utils.Error("删除用户失败"))
+	if err := ctrl.userService.PurgeUser(id); err != nil {
+		respondError(c, err, "purge_user_failed")
 		return
 	}
 
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/i18n"
+	"react-go-admin-backend/middleware"
+	"react-go-admin-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+var (
+	// usernamePattern 用户名：字母开头，仅包含字母、数字和下划线，3-50 位
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{2,49}$`)
+	// roleCodePattern 角色代码：小写字母开头，仅包含小写字母、数字和下划线，2-50 位
+	roleCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+)
+
+// customValidation 自定义校验规则，提示取自语言包中的 key，参数为字段名及 args
+type customValidation struct {
+	tag  string
+	fn   validator.Func
+	key  string
+	args []interface{}
+}
+
+// customValidations 返回自定义校验规则；电话号码格式取自配置，需在配置加载后调用
+func customValidations() []customValidation {
+	return []customValidation{
+		{tag: "username", fn: matchPattern(usernamePattern), key: "validate_username"},
+		{tag: "phone", fn: matchPattern(regexp.MustCompile(config.GetValidation().PhonePattern)), key: "validate_phone"},
+		{tag: "role_code", fn: matchPattern(roleCodePattern), key: "validate_role_code"},
+		{
+			tag: "locale",
+			fn: func(fl validator.FieldLevel) bool {
+				value := fl.Field().String()
+				return value == "" || i18n.IsSupported(value)
+			},
+			key:  "validate_locale",
+			args: []interface{}{strings.Join(i18n.Supported(), ", ")},
+		},
+	}
+}
+
+// validatorLocales 校验器翻译器与语言包的对应关系
+var validatorLocales = map[string]string{
+	"zh": i18n.ZhCN,
+	"en": i18n.EnUS,
+}
+
+var (
+	validatorOnce sync.Once
+	translators   *ut.UniversalTranslator
+)
+
+// setupValidator 为 gin 的校验器注册自定义规则及中英文翻译，字段名使用 json 标签
+func setupValidator() {
+	validatorOnce.Do(func() {
+		zhLocale := zh.New()
+		translators = ut.New(zhLocale, zhLocale, en.New())
+
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		zhTrans, _ := translators.GetTranslator("zh")
+		enTrans, _ := translators.GetTranslator("en")
+		if err := zhTranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+			log.Printf("注册中文校验提示失败: %v", err)
+		}
+		if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+			log.Printf("注册英文校验提示失败: %v", err)
+		}
+
+		for _, custom := range customValidations() {
+			if err := v.RegisterValidation(custom.tag, custom.fn); err != nil {
+				log.Printf("注册校验规则 %s 失败: %v", custom.tag, err)
+				continue
+			}
+			// 字段名占位符 {0} 由校验器在翻译时替换
+			args := append([]interface{}{"{0}"}, custom.args...)
+			for name, locale := range validatorLocales {
+				trans, _ := translators.GetTranslator(name)
+				registerTranslation(v, trans, custom.tag, i18n.T(locale, custom.key, args...))
+			}
+		}
+	})
+}
+
+// matchPattern 返回按正则校验字符串字段的规则，空值交由 required/omitempty 处理
+func matchPattern(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return value == "" || pattern.MatchString(value)
+	}
+}
+
+// registerTranslation 注册自定义规则的提示
+func registerTranslation(v *validator.Validate, trans ut.Translator, tag, text string) {
+	err := v.RegisterTranslation(tag, trans,
+		func(trans ut.Translator) error {
+			return trans.Add(tag, text, true)
+		},
+		func(trans ut.Translator, fe validator.FieldError) string {
+			msg, err := trans.T(tag, fe.Field())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		},
+	)
+	if err != nil {
+		log.Printf("注册校验提示 %s 失败: %v", tag, err)
+	}
+}
+
+// requestTranslator 按请求语言选择校验提示的语言，默认中文
+func requestTranslator(c *gin.Context) ut.Translator {
+	setupValidator()
+
+	// zh-CN、en-US 等统一取主语言
+	locale := strings.ToLower(strings.SplitN(middleware.GetLocale(c), "-", 2)[0])
+	trans, _ := translators.FindTranslator(locale)
+	return trans
+}
+
+// respondBindError 请求绑定失败时返回 400；字段校验失败时返回按请求语言翻译的字段级错误
+func respondBindError(c *gin.Context, err error) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		respondBadRequest(c)
+		return
+	}
+
+	trans := requestTranslator(c)
+	fields := make([]utils.FieldError, 0, len(errs))
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		message := fe.Translate(trans)
+		fields = append(fields, utils.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: message,
+		})
+		messages = append(messages, message)
+	}
+
+	c.JSON(http.StatusBadRequest, utils.ErrorWithFields(strings.Join(messages, "; "), fields))
+}
+
+// fieldPath 返回去掉请求结构体名的字段路径，如 userIds[0]
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
@@ -1,33 +1,492 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 const (
-	// 服务器配置
-	ServerPort = ":8080"
+	// ModeDevelopment 开发模式
+	ModeDevelopment = "development"
+	// ModeProduction 生产模式
+	ModeProduction = "production"
 
-	// JWT 配置
-	JWTSecret     = "your-secret-key-change-in-production"
-	JWTExpireHour = 24 * 7 // 7 天
+	// DriverSQLite SQLite 数据库驱动
+	DriverSQLite = "sqlite"
+	// DriverMySQL MySQL 数据库驱动
+	DriverMySQL = "mysql"
+	// DriverPostgres PostgreSQL 数据库驱动
+	DriverPostgres = "postgres"
 
-	// 数据库配置
-	DBPath = "./data.db"
+	// NotifyDriverLog 通知写入日志
+	NotifyDriverLog = "log"
+	// NotifyDriverFile 通知追加写入文件
+	NotifyDriverFile = "file"
+
+	// DefaultJWTSecret 默认 JWT 密钥，生产模式下禁止使用
+	DefaultJWTSecret = "your-secret-key-change-in-production"
 )
 
+// Config 应用配置
+type Config struct {
+	Mode       string           `yaml:"mode"` // development / production
+	Server     ServerConfig     `yaml:"server"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	Database   DatabaseConfig   `yaml:"database"`
+	CORS       CORSConfig       `yaml:"cors"`
+	Log        LogConfig        `yaml:"log"`
+	Seed       SeedConfig       `yaml:"seed"`
+	Security   SecurityConfig   `yaml:"security"`
+	Notify     NotifyConfig     `yaml:"notify"`
+	Validation ValidationConfig `yaml:"validation"`
+}
+
+// ServerConfig 服务器配置
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	// TrustedProxies 受信任的反向代理 IP 或网段，仅来自这些地址的请求才采用 X-Forwarded-For 等头中的客户端 IP；默认不信任任何代理
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// JWTConfig JWT 配置
+type JWTConfig struct {
+	Secret                  string `yaml:"secret"`
+	AccessTokenExpireMinute int    `yaml:"access_token_expire_minute"`
+	RefreshTokenExpireHour  int    `yaml:"refresh_token_expire_hour"`
+	TokenCleanupMinute      int    `yaml:"token_cleanup_minute"`
+}
+
+// DatabaseConfig 数据库配置
+type DatabaseConfig struct {
+	Driver                string `yaml:"driver"` // sqlite / mysql / postgres
+	DSN                   string `yaml:"dsn"`
+	MaxOpenConns          int    `yaml:"max_open_conns"`
+	MaxIdleConns          int    `yaml:"max_idle_conns"`
+	ConnMaxLifetimeMinute int    `yaml:"conn_max_lifetime_minute"`
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins"`
+}
+
+// SeedConfig 种子数据配置
+type SeedConfig struct {
+	File          string `yaml:"file"` // 外部种子文件，为空时使用内置数据
+	AdminUsername string `yaml:"admin_username"`
+	AdminPassword string `yaml:"admin_password"` // 为空时生成随机密码并打印到日志
+	AdminEmail    string `yaml:"admin_email"`
+	AdminRole     string `yaml:"admin_role"`
+}
+
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	LoginMaxFailures         int `yaml:"login_max_failures"`          // 窗口内连续失败次数达到该值时锁定账号
+	LoginFailureWindowMinute int `yaml:"login_failure_window_minute"` // 失败次数统计窗口
+	LoginLockoutMinute       int `yaml:"login_lockout_minute"`        // 账号锁定时长
+	LoginIPMaxAttempts       int `yaml:"login_ip_max_attempts"`       // 单个 IP 在窗口内允许的凭据错误次数
+	LoginIPWindowMinute      int `yaml:"login_ip_window_minute"`      // IP 限流窗口
+	ResetTokenExpireMinute   int `yaml:"reset_token_expire_minute"`   // 密码重置令牌有效期
+
+	MFAIssuer                string `yaml:"mfa_issuer"`                  // 两步验证应用中显示的发行方
+	MFAChallengeExpireMinute int    `yaml:"mfa_challenge_expire_minute"` // 登录第二步验证的有效期
+
+	Password PasswordPolicyConfig `yaml:"password"`
+}
+
+// PasswordPolicyConfig 密码策略配置
+type PasswordPolicyConfig struct {
+	MinLength        int  `yaml:"min_length"`
+	RequireUppercase bool `yaml:"require_uppercase"`
+	RequireLowercase bool `yaml:"require_lowercase"`
+	RequireDigit     bool `yaml:"require_digit"`
+	RequireSpecial   bool `yaml:"require_special"`
+	HistoryCount     int  `yaml:"history_count"` // 不允许重复使用最近 N 个密码，0 表示不限制
+	MaxAgeDay        int  `yaml:"max_age_day"`   // 密码最长使用天数，到期后登录需修改密码，0 表示不限制
+}
+
+// NotifyConfig 通知配置
+type NotifyConfig struct {
+	Driver   string `yaml:"driver"`    // log：写入日志；file：追加写入文件
+	File     string `yaml:"file"`      // driver 为 file 时的输出文件
+	ResetURL string `yaml:"reset_url"` // 密码重置链接模板，{token} 会被替换为重置令牌
+}
+
+// ValidationConfig 请求参数校验配置
+type ValidationConfig struct {
+	PhonePattern string `yaml:"phone_pattern"` // 电话号码格式（正则），默认 E.164 格式，可带 + 前缀
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level string `yaml:"level"` // debug / info / warn / error
+}
+
+var cfg = Default()
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Mode: ModeDevelopment,
+		Server: ServerConfig{
+			Port: ":8080",
+		},
+		JWT: JWTConfig{
+			Secret:                  DefaultJWTSecret,
+			AccessTokenExpireMinute: 30,     // 访问令牌 30 分钟
+			RefreshTokenExpireHour:  24 * 7, // 刷新令牌 7 天
+			TokenCleanupMinute:      60,     // 过期令牌清理间隔
+		},
+		Database: DatabaseConfig{
+			Driver:                DriverSQLite,
+			DSN:                   "./data.db",
+			MaxOpenConns:          25,
+			MaxIdleConns:          10,
+			ConnMaxLifetimeMinute: 60,
+		},
+		CORS: CORSConfig{
+			AllowOrigins: []string{"http://localhost:5173", "http://localhost:5174"},
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Seed: SeedConfig{
+			AdminUsername: "admin",
+			AdminEmail:    "admin@example.com",
+			AdminRole:     "admin",
+		},
+		Security: SecurityConfig{
+			LoginMaxFailures:         5,
+			LoginFailureWindowMinute: 15,
+			LoginLockoutMinute:       15,
+			LoginIPMaxAttempts:       20,
+			LoginIPWindowMinute:      1,
+			ResetTokenExpireMinute:   30,
+			MFAIssuer:                "React Go Admin",
+			MFAChallengeExpireMinute: 5,
+			Password: PasswordPolicyConfig{
+				MinLength:        8,
+				RequireUppercase: true,
+				RequireLowercase: true,
+				RequireDigit:     true,
+				HistoryCount:     5,
+				MaxAgeDay:        90,
+			},
+		},
+		Notify: NotifyConfig{
+			Driver:   NotifyDriverLog,
+			File:     "./notifications.log",
+			ResetURL: "http://localhost:5173/reset-password?token={token}",
+		},
+		Validation: ValidationConfig{
+			PhonePattern: `^\+?[1-9]\d{6,14}$`,
+		},
+	}
+}
+
+// Load 加载配置：默认值 -> 配置文件（可选）-> 环境变量，最后进行校验
+func Load(path string) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if err == nil {
+			if err := yaml.Unmarshal(data, c); err != nil {
+				return nil, fmt.Errorf("解析配置文件失败: %w", err)
+			}
+		}
+	}
+
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg = c
+	return c, nil
+}
+
+// Get 获取当前配置
+func Get() *Config {
+	return cfg
+}
+
+// applyEnv 使用环境变量覆盖配置
+func (c *Config) applyEnv() error {
+	setString := func(key string, dst *string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = v
+		}
+	}
+	setInt := func(key string, dst *int) error {
+		v, ok := os.LookupEnv(key)
+		if !ok {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 必须为整数", key)
+		}
+		*dst = n
+		return nil
+	}
+
+	setString("APP_MODE", &c.Mode)
+	setString("SERVER_PORT", &c.Server.Port)
+	setString("JWT_SECRET", &c.JWT.Secret)
+	setString("DB_DRIVER", &c.Database.Driver)
+	setString("DB_DSN", &c.Database.DSN)
+	setString("LOG_LEVEL", &c.Log.Level)
+	setString("SEED_FILE", &c.Seed.File)
+	setString("ADMIN_USERNAME", &c.Seed.AdminUsername)
+	setString("ADMIN_PASSWORD", &c.Seed.AdminPassword)
+	setString("ADMIN_EMAIL", &c.Seed.AdminEmail)
+	setString("NOTIFY_DRIVER", &c.Notify.Driver)
+	setString("NOTIFY_FILE", &c.Notify.File)
+	setString("NOTIFY_RESET_URL", &c.Notify.ResetURL)
+	setString("VALIDATION_PHONE_PATTERN", &c.Validation.PhonePattern)
+
+	if err := setInt("JWT_ACCESS_TOKEN_EXPIRE_MINUTE", &c.JWT.AccessTokenExpireMinute); err != nil {
+		return err
+	}
+	if err := setInt("JWT_REFRESH_TOKEN_EXPIRE_HOUR", &c.JWT.RefreshTokenExpireHour); err != nil {
+		return err
+	}
+	if err := setInt("JWT_TOKEN_CLEANUP_MINUTE", &c.JWT.TokenCleanupMinute); err != nil {
+		return err
+	}
+	if err := setInt("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns); err != nil {
+		return err
+	}
+	if err := setInt("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns); err != nil {
+		return err
+	}
+	if err := setInt("DB_CONN_MAX_LIFETIME_MINUTE", &c.Database.ConnMaxLifetimeMinute); err != nil {
+		return err
+	}
+	if err := setInt("LOGIN_MAX_FAILURES", &c.Security.LoginMaxFailures); err != nil {
+		return err
+	}
+	if err := setInt("LOGIN_LOCKOUT_MINUTE", &c.Security.LoginLockoutMinute); err != nil {
+		return err
+	}
+	if err := setInt("LOGIN_IP_MAX_ATTEMPTS", &c.Security.LoginIPMaxAttempts); err != nil {
+		return err
+	}
+	if err := setInt("PASSWORD_MIN_LENGTH", &c.Security.Password.MinLength); err != nil {
+		return err
+	}
+	if err := setInt("PASSWORD_HISTORY_COUNT", &c.Security.Password.HistoryCount); err != nil {
+		return err
+	}
+	if err := setInt("PASSWORD_MAX_AGE_DAY", &c.Security.Password.MaxAgeDay); err != nil {
+		return err
+	}
+
+	if v, ok := os.LookupEnv("CORS_ALLOW_ORIGINS"); ok {
+		c.CORS.AllowOrigins = splitList(v)
+	}
+	if v, ok := os.LookupEnv("SERVER_TRUSTED_PROXIES"); ok {
+		c.Server.TrustedProxies = splitList(v)
+	}
+
+	// 端口允许只写数字
+	if c.Server.Port != "" && !strings.Contains(c.Server.Port, ":") {
+		c.Server.Port = ":" + c.Server.Port
+	}
+	return nil
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	var errs []string
+
+	switch c.Mode {
+	case ModeDevelopment, ModeProduction:
+	default:
+		errs = append(errs, fmt.Sprintf("mode 必须为 %s 或 %s", ModeDevelopment, ModeProduction))
+	}
+
+	if c.Server.Port == "" {
+		errs = append(errs, "server.port 不能为空")
+	}
+
+	if c.JWT.Secret == "" {
+		errs = append(errs, "jwt.secret 不能为空")
+	}
+	if c.IsProduction() && c.JWT.Secret == DefaultJWTSecret {
+		errs = append(errs, "生产模式下必须修改默认的 jwt.secret")
+	}
+	if c.JWT.AccessTokenExpireMinute <= 0 {
+		errs = append(errs, "jwt.access_token_expire_minute 必须大于 0")
+	}
+	if c.JWT.RefreshTokenExpireHour <= 0 {
+		errs = append(errs, "jwt.refresh_token_expire_hour 必须大于 0")
+	}
+	if c.JWT.TokenCleanupMinute <= 0 {
+		errs = append(errs, "jwt.token_cleanup_minute 必须大于 0")
+	}
+
+	switch c.Database.Driver {
+	case DriverSQLite, DriverMySQL, DriverPostgres:
+	default:
+		errs = append(errs, "database.driver 必须为 sqlite、mysql 或 postgres")
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, "database.dsn 不能为空")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 || c.Database.ConnMaxLifetimeMinute < 0 {
+		errs = append(errs, "数据库连接池配置不能为负数")
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		errs = append(errs, "cors.allow_origins 不能为空")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, "log.level 必须为 debug、info、warn 或 error")
+	}
+
+	if c.Seed.AdminUsername == "" || c.Seed.AdminRole == "" {
+		errs = append(errs, "seed.admin_username 和 seed.admin_role 不能为空")
+	}
+
+	if c.Security.LoginMaxFailures <= 0 || c.Security.LoginFailureWindowMinute <= 0 || c.Security.LoginLockoutMinute <= 0 {
+		errs = append(errs, "security.login_max_failures、login_failure_window_minute 和 login_lockout_minute 必须大于 0")
+	}
+	if c.Security.LoginIPMaxAttempts <= 0 || c.Security.LoginIPWindowMinute <= 0 {
+		errs = append(errs, "security.login_ip_max_attempts 和 login_ip_window_minute 必须大于 0")
+	}
+	if c.Security.ResetTokenExpireMinute <= 0 {
+		errs = append(errs, "security.reset_token_expire_minute 必须大于 0")
+	}
+	if c.Security.MFAIssuer == "" || c.Security.MFAChallengeExpireMinute <= 0 {
+		errs = append(errs, "security.mfa_issuer 不能为空且 mfa_challenge_expire_minute 必须大于 0")
+	}
+	if c.Security.Password.MinLength <= 0 {
+		errs = append(errs, "security.password.min_length 必须大于 0")
+	}
+	if c.Security.Password.HistoryCount < 0 || c.Security.Password.MaxAgeDay < 0 {
+		errs = append(errs, "security.password.history_count 和 max_age_day 不能为负数")
+	}
+
+	switch c.Notify.Driver {
+	case NotifyDriverLog:
+	case NotifyDriverFile:
+		if c.Notify.File == "" {
+			errs = append(errs, "notify.driver 为 file 时 notify.file 不能为空")
+		}
+	default:
+		errs = append(errs, "notify.driver 必须为 log 或 file")
+	}
+
+	if _, err := regexp.Compile(c.Validation.PhonePattern); err != nil || c.Validation.PhonePattern == "" {
+		errs = append(errs, "validation.phone_pattern 必须是有效的正则表达式")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("配置校验失败: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// IsProduction 是否为生产模式
+func (c *Config) IsProduction() bool {
+	return c.Mode == ModeProduction
+}
+
 // GetServerPort 获取服务器端口
 func GetServerPort() string {
-	return ServerPort
+	return cfg.Server.Port
+}
+
+// GetTrustedProxies 获取受信任的反向代理
+func GetTrustedProxies() []string {
+	return cfg.Server.TrustedProxies
 }
 
 // GetJWTSecret 获取 JWT 密钥
 func GetJWTSecret() string {
-	return JWTSecret
+	return cfg.JWT.Secret
+}
+
+// GetAccessTokenExpire 获取访问令牌有效期
+func GetAccessTokenExpire() time.Duration {
+	return time.Duration(cfg.JWT.AccessTokenExpireMinute) * time.Minute
+}
+
+// GetRefreshTokenExpire 获取刷新令牌有效期
+func GetRefreshTokenExpire() time.Duration {
+	return time.Duration(cfg.JWT.RefreshTokenExpireHour) * time.Hour
+}
+
+// GetTokenCleanupInterval 获取过期令牌清理间隔
+func GetTokenCleanupInterval() time.Duration {
+	return time.Duration(cfg.JWT.TokenCleanupMinute) * time.Minute
+}
+
+// GetDBDSN 获取数据库连接串
+func GetDBDSN() string {
+	return cfg.Database.DSN
+}
+
+// GetDatabase 获取数据库配置
+func GetDatabase() DatabaseConfig {
+	return cfg.Database
+}
+
+// GetCORSAllowOrigins 获取允许跨域的来源
+func GetCORSAllowOrigins() []string {
+	return cfg.CORS.AllowOrigins
+}
+
+// GetSeed 获取种子数据配置
+func GetSeed() SeedConfig {
+	return cfg.Seed
+}
+
+// GetSecurity 获取安全配置
+func GetSecurity() SecurityConfig {
+	return cfg.Security
+}
+
+// GetNotify 获取通知配置
+func GetNotify() NotifyConfig {
+	return cfg.Notify
+}
+
+// GetValidation 获取请求参数校验配置
+func GetValidation() ValidationConfig {
+	return cfg.Validation
 }
 
-// GetJWTExpireHour 获取 JWT 过期时间（小时）
-func GetJWTExpireHour() int {
-	return JWTExpireHour
+// GetLogLevel 获取日志级别
+func GetLogLevel() string {
+	return cfg.Log.Level
 }
 
-// GetDBPath 获取数据库路径
-func GetDBPath() string {
-	return DBPath
+// splitList 解析逗号分隔的环境变量值，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+
+	// DefaultLocale 默认语言，其他语言缺少的消息回退到默认语言
+	DefaultLocale = ZhCN
+)
+
+//go:embed locales/*.yaml
+var bundles embed.FS
+
+// catalogs 各语言的消息目录，键为错误键或消息键
+var catalogs = loadCatalogs()
+
+// loadCatalogs 加载内置的语言包，文件名即语言标识
+func loadCatalogs() map[string]map[string]string {
+	entries, err := bundles.ReadDir("locales")
+	if err != nil {
+		log.Fatalf("读取语言包失败: %v", err)
+	}
+
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := bundles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			log.Fatalf("读取语言包 %s 失败: %v", entry.Name(), err)
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			log.Fatalf("解析语言包 %s 失败: %v", entry.Name(), err)
+		}
+		result[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = messages
+	}
+	return result
+}
+
+// Supported 返回支持的语言列表
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// IsSupported 判断是否为支持的语言（需为完整标识，如 en-US）
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Normalize 将 zh、zh_cn、en-GB 等语言标识匹配为支持的语言，无法匹配时返回空串
+func Normalize(tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return ""
+	}
+	for locale := range catalogs {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+
+	// 仅主语言相同时按主语言匹配
+	primary := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	for _, locale := range Supported() {
+		if strings.ToLower(strings.SplitN(locale, "-", 2)[0]) == primary {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Match 按 Accept-Language 中的优先级（q 值）选择支持的语言，没有匹配时返回默认语言
+func Match(acceptLanguage string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	for _, c := range candidates {
+		if locale := Normalize(c.tag); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// T 返回指定语言下 key 对应的消息，args 按 fmt 格式化；
+// 缺少该消息时回退到默认语言，仍不存在时返回 key 本身
+func T(locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
@@ -1,26 +1,61 @@
 package main
 
 import (
+	"flag"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"log"
+	"os"
 	"react-go-admin-backend/api"
 	"react-go-admin-backend/config"
 	"react-go-admin-backend/models"
+	"react-go-admin-backend/services"
 )
 
 func main() {
+	// 加载配置，可通过 -config 参数或 CONFIG_FILE 环境变量指定配置文件
+	configPath := flag.String("config", getEnv("CONFIG_FILE", "config.yaml"), "配置文件路径")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// 子命令：migrate up|down [n]|status
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			log.Fatal("迁移失败:", err)
+		}
+		return
+	}
+
 	// 初始化数据库
 	if err := models.InitDB(); err != nil {
 		log.Fatal("数据库初始化失败:", err)
 	}
 
+	// 通知发送器（重置密码等）
+	services.SetNotifier(services.NewNotifier(config.GetNotify()))
+
+	// 定期清理过期令牌
+	tokenService := &services.TokenService{}
+	tokenService.StartCleanup(config.GetTokenCleanupInterval())
+
 	// 创建 Gin 引擎
 	r := gin.Default()
 
+	// 仅信任配置的反向代理，否则客户端可伪造 X-Forwarded-For 绕过按 IP 的登录限制
+	if err := r.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		log.Fatal("受信任代理配置无效:", err)
+	}
+
 	// 配置 CORS
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:5174"},
+		AllowOrigins:     config.GetCORSAllowOrigins(),
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
@@ -37,3 +72,11 @@ func main() {
 		log.Fatal("服务器启动失败:", err)
 	}
 }
+
+// getEnv 读取环境变量，未设置时返回默认值
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
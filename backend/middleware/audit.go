@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/services"
+)
+
+// Audit 审计中间件，需在 AuthMiddleware 之后使用。
+// 处理前后分别对路径参数 :id 指定的资源做快照，操作成功时记录字段差异；创建操作从响应的 data.id 获取资源 ID。
+func Audit(resourceType, action string) gin.HandlerFunc {
+	return audit(resourceType, action, func(c *gin.Context) []uint {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			return nil
+		}
+		return []uint{uint(id)}
+	})
+}
+
+// AuditBatch 批量操作审计中间件，资源 ID 取自请求体 JSON 中的 field 字段，每个发生变化的资源记录一条日志
+func AuditBatch(resourceType, action, field string) gin.HandlerFunc {
+	return audit(resourceType, action, func(c *gin.Context) []uint {
+		var ids []uint
+		if err := peekJSONField(c, field, &ids); err != nil {
+			return nil
+		}
+		return ids
+	})
+}
+
+// AuditSelf 用户修改自己账号的审计中间件，需在 AuthMiddleware 之后使用，资源为当前登录用户
+func AuditSelf(action string) gin.HandlerFunc {
+	return audit(services.AuditResourceUser, action, func(c *gin.Context) []uint {
+		if userID := c.GetUint("user_id"); userID != 0 {
+			return []uint{userID}
+		}
+		return nil
+	})
+}
+
+// AuditPasswordReset 使用重置令牌设置密码的审计中间件。接口无需登录，以请求体中令牌所属的用户作为操作者和资源
+func AuditPasswordReset(action string) gin.HandlerFunc {
+	resetService := &services.PasswordResetService{}
+
+	return audit(services.AuditResourceUser, action, func(c *gin.Context) []uint {
+		var token string
+		if err := peekJSONField(c, "token", &token); err != nil {
+			return nil
+		}
+		user, err := resetService.TokenUser(token)
+		if err != nil {
+			return nil
+		}
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		return []uint{user.ID}
+	})
+}
+
+// peekJSONField 读取请求体 JSON 中的 field 字段，并恢复请求体供后续处理器绑定
+func peekJSONField(c *gin.Context, field string, value interface{}) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	return json.Unmarshal(payload[field], value)
+}
+
+// audit 审计中间件的通用实现
+func audit(resourceType, action string, resolveIDs func(c *gin.Context) []uint) gin.HandlerFunc {
+	auditService := &services.AuditService{}
+
+	return func(c *gin.Context) {
+		ids := resolveIDs(c)
+		before := make(map[uint]map[string]interface{}, len(ids))
+		for _, id := range ids {
+			snapshot, err := auditService.Snapshot(resourceType, id)
+			if err != nil {
+				log.Printf("审计快照失败 %s#%d: %v", resourceType, id, err)
+			}
+			before[id] = snapshot
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// 仅记录业务处理成功的操作
+		var resp struct {
+			Code int `json:"code"`
+			Data struct {
+				ID uint `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.body.Bytes(), &resp); err != nil || resp.Code != 200 {
+			return
+		}
+		if len(ids) == 0 && resp.Data.ID != 0 {
+			ids = []uint{resp.Data.ID}
+		}
+
+		userID, _ := c.Get("user_id")
+		username, _ := c.Get("username")
+		for _, id := range ids {
+			after, err := auditService.Snapshot(resourceType, id)
+			if err != nil {
+				log.Printf("审计快照失败 %s#%d: %v", resourceType, id, err)
+				continue
+			}
+			changes := auditService.Diff(before[id], after)
+			if len(changes) == 0 {
+				continue
+			}
+
+			entry := &models.AuditLog{
+				Action:       action,
+				ResourceType: resourceType,
+				ResourceID:   id,
+				Changes:      changes,
+				IP:           c.ClientIP(),
+				UserAgent:    c.Request.UserAgent(),
+			}
+			entry.UserID, _ = userID.(uint)
+			entry.Username, _ = username.(string)
+			if err := auditService.Record(entry); err != nil {
+				log.Printf("写入审计日志失败: %v", err)
+			}
+		}
+	}
+}
+
+// responseRecorder 记录响应内容，用于判断操作是否成功
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
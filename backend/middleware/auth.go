@@ -1,43 +1,96 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"react-go-admin-backend/services"
 	"react-go-admin-backend/utils"
 )
 
 // AuthMiddleware JWT 认证中间件
 func AuthMiddleware() gin.HandlerFunc {
+	tokenService := &services.TokenService{}
+
 	return func(c *gin.Context) {
 		// 获取 Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, utils.ErrorWithCode(401, "未授权"))
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, utils.ErrKeyUnauthorized)
 			return
 		}
 
 		// 检查格式是否为 Bearer token
 		parts := strings.SplitN(authHeader, " ", 2)
 		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.JSON(http.StatusUnauthorized, utils.ErrorWithCode(401, "token 格式错误"))
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, utils.ErrKeyTokenMalformed)
 			return
 		}
 
 		// 解析 token
 		claims, err := utils.ParseToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, utils.ErrorWithCode(401, "token 无效或已过期"))
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, utils.ErrKeyTokenInvalid)
+			return
+		}
+
+		// 检查 token 是否已被吊销
+		user, err := tokenService.ValidateAccessToken(claims)
+		if errors.Is(err, services.ErrAccessTokenRevoked) {
+			abortWithError(c, http.StatusUnauthorized, utils.ErrKeyTokenInvalid)
+			return
+		}
+		if err != nil {
+			log.Printf("校验 token 失败: %v", err)
+			abortWithError(c, http.StatusInternalServerError, utils.ErrKeyInternal)
 			return
 		}
 
 		// 将用户信息存储到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("claims", claims)
+		c.Set("must_change_password", user.MustChangePassword)
+		c.Set("mfa_enabled", user.MFAEnabled)
+		if user.Locale != "" {
+			c.Set(localeKey, user.Locale)
+		}
+
+		c.Next()
+	}
+}
+
+// PasswordChangeGuard 要求修改密码的用户在修改密码前只能访问认证相关接口
+func PasswordChangeGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("must_change_password") {
+			abortWithError(c, http.StatusForbidden, utils.ErrKeyPasswordChangeRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// MFASetupGuard 所属角色要求两步验证的用户在启用两步验证前只能访问认证相关接口
+func MFASetupGuard() gin.HandlerFunc {
+	mfaService := &services.MFAService{}
+
+	return func(c *gin.Context) {
+		if !c.GetBool("mfa_enabled") {
+			required, err := mfaService.Required(c.GetUint("user_id"))
+			if err != nil {
+				abortWithError(c, http.StatusInternalServerError, "mfa_check_failed")
+				return
+			}
+			if required {
+				abortWithError(c, http.StatusForbidden, utils.ErrKeyMFASetupRequired)
+				return
+			}
+		}
 
 		c.Next()
 	}
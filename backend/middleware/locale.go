@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"react-go-admin-backend/i18n"
+	"react-go-admin-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localeKey 上下文中保存请求语言的键
+const localeKey = "locale"
+
+// Locale 按 Accept-Language 确定请求语言，登录用户设置了语言偏好时由 AuthMiddleware 覆盖
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(localeKey, i18n.Match(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// GetLocale 返回当前请求的语言
+func GetLocale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	return i18n.Match(c.GetHeader("Accept-Language"))
+}
+
+// abortWithError 按当前请求语言返回错误响应并终止请求
+func abortWithError(c *gin.Context, status int, key string) {
+	c.JSON(status, utils.Fail(status, key, i18n.T(GetLocale(c), key)))
+	c.Abort()
+}
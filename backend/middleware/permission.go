@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"react-go-admin-backend/services"
+	"react-go-admin-backend/utils"
+)
+
+// RequirePermission 权限校验中间件，需在 AuthMiddleware 之后使用
+func RequirePermission(code string) gin.HandlerFunc {
+	userService := &services.UserService{}
+
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			abortWithError(c, http.StatusUnauthorized, utils.ErrKeyUnauthorized)
+			return
+		}
+
+		// 根据用户角色校验权限
+		ok, err := userService.HasPermission(userID.(uint), code)
+		if err != nil || !ok {
+			abortWithError(c, http.StatusForbidden, utils.ErrKeyForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
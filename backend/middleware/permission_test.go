@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"react-go-admin-backend/migrations"
+	"react-go-admin-backend/models"
+)
+
+// setupTestDB 使用执行过全部迁移的内存 SQLite 数据库替换 models.DB，测试结束后恢复
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接池失败: %v", err)
+	}
+	// 每个连接都是独立的内存数据库，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	previous := models.DB
+	models.DB = db
+	t.Cleanup(func() {
+		models.DB = previous
+		sqlDB.Close()
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const code = "system:user:view"
+
+	tests := []struct {
+		name string
+		// setup 准备用户的角色，返回用户 ID，0 表示请求未经认证
+		setup func(t *testing.T, permission models.Permission) uint
+		want  int
+	}{
+		{
+			name: "角色拥有权限",
+			setup: func(t *testing.T, permission models.Permission) uint {
+				return createUserWithRole(t, models.Role{Code: "viewer", Status: 1, Permissions: []models.Permission{permission}})
+			},
+			want: http.StatusOK,
+		},
+		{
+			name: "角色没有权限",
+			setup: func(t *testing.T, permission models.Permission) uint {
+				return createUserWithRole(t, models.Role{Code: "guest", Status: 1})
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "唯一的角色被禁用",
+			setup: func(t *testing.T, permission models.Permission) uint {
+				userID := createUserWithRole(t, models.Role{Code: "viewer", Status: 1, Permissions: []models.Permission{permission}})
+				mustExec(t, models.DB.Model(&models.Role{}).Where("code = ?", "viewer").Update("status", 0))
+				return userID
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "唯一的角色已删除",
+			setup: func(t *testing.T, permission models.Permission) uint {
+				userID := createUserWithRole(t, models.Role{Code: "viewer", Status: 1, Permissions: []models.Permission{permission}})
+				mustExec(t, models.DB.Where("code = ?", "viewer").Delete(&models.Role{}))
+				return userID
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:  "未经认证",
+			setup: func(t *testing.T, permission models.Permission) uint { return 0 },
+			want:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			permission := models.Permission{Name: "用户查看", Code: code, Type: 2}
+			mustExec(t, models.DB.Create(&permission))
+			userID := tt.setup(t, permission)
+
+			router := gin.New()
+			router.GET("/users", func(c *gin.Context) {
+				if userID != 0 {
+					c.Set("user_id", userID)
+				}
+			}, RequirePermission(code), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+			if w.Code != tt.want {
+				t.Fatalf("状态码为 %d，期望 %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// createUserWithRole 创建角色及只拥有该角色的用户，返回用户 ID
+func createUserWithRole(t *testing.T, role models.Role) uint {
+	t.Helper()
+
+	role.Name = role.Code
+	mustExec(t, models.DB.Create(&role))
+	user := models.User{Username: "alice", Password: "-", Status: 1, Roles: []models.Role{role}}
+	mustExec(t, models.DB.Create(&user))
+	return user.ID
+}
+
+// mustExec 查询或更新失败时终止测试
+func mustExec(t *testing.T, result *gorm.DB) {
+	t.Helper()
+
+	if result.Error != nil {
+		t.Fatalf("数据库操作失败: %v", result.Error)
+	}
+}
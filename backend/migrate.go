@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"react-go-admin-backend/migrations"
+	"react-go-admin-backend/models"
+)
+
+// runMigrate 执行迁移命令
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: migrate up|down [n]|status")
+	}
+
+	if err := models.ConnectDB(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrations.Up(models.DB)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("回滚步数必须为正整数")
+			}
+			steps = n
+		}
+		return migrations.Down(models.DB, steps)
+	case "status":
+		statuses, err := migrations.Status(models.DB)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "未执行"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s  %-40s  %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	default:
+		return fmt.Errorf("未知的迁移命令: %s", args[0])
+	}
+}
@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 基线迁移：表结构快照，后续模型变更不应修改此文件
+func init() {
+	type Permission struct {
+		ID          uint   `gorm:"primarykey"`
+		Name        string `gorm:"size:50;not null"`
+		Code        string `gorm:"uniqueIndex;size:50;not null"`
+		ParentCode  string `gorm:"size:50"`
+		Path        string `gorm:"size:100"`
+		Type        int    `gorm:"default:1"`
+		Sort        int    `gorm:"default:0"`
+		Description string `gorm:"size:255"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+
+	type Role struct {
+		ID          uint   `gorm:"primarykey"`
+		Name        string `gorm:"size:50;not null"`
+		Code        string `gorm:"uniqueIndex;size:50;not null"`
+		Description string `gorm:"size:255"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+
+		Permissions []Permission `gorm:"many2many:role_permissions"`
+	}
+
+	type User struct {
+		ID           uint   `gorm:"primarykey"`
+		Username     string `gorm:"uniqueIndex;size:50;not null"`
+		Password     string `gorm:"size:255;not null"`
+		Realname     string `gorm:"size:50"`
+		Email        string `gorm:"size:100"`
+		Phone        string `gorm:"size:20"`
+		Avatar       string `gorm:"size:255"`
+		Status       int    `gorm:"default:1"`
+		TokenVersion int    `gorm:"default:0"`
+		CreatedAt    time.Time
+		UpdatedAt    time.Time
+
+		Roles []Role `gorm:"many2many:user_roles"`
+	}
+
+	type RefreshToken struct {
+		ID         uint   `gorm:"primarykey"`
+		UserID     uint   `gorm:"index;not null"`
+		TokenHash  string `gorm:"uniqueIndex;size:64;not null"`
+		FamilyID   string `gorm:"index;size:32;not null"`
+		ExpiresAt  time.Time
+		RevokedAt  *time.Time
+		ReplacedBy *uint
+		CreatedAt  time.Time
+	}
+
+	type RevokedToken struct {
+		ID        uint      `gorm:"primarykey"`
+		JTI       string    `gorm:"uniqueIndex;size:32;not null"`
+		UserID    uint      `gorm:"index"`
+		ExpiresAt time.Time `gorm:"index"`
+		CreatedAt time.Time
+	}
+
+	register(Migration{
+		Version: "0001",
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			// 对于旧版 AutoMigrate 创建的数据库，只会补齐缺失的表和列
+			return tx.AutoMigrate(&User{}, &Role{}, &Permission{}, &RefreshToken{}, &RevokedToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				"user_roles", "role_permissions", &RevokedToken{}, &RefreshToken{}, &User{}, &Role{}, &Permission{},
+			)
+		},
+	})
+}
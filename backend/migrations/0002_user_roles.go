@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// 将旧版 users.role_id 单角色字段迁移到 user_roles 关联表
+func init() {
+	type User struct {
+		RoleID *uint
+	}
+
+	register(Migration{
+		Version: "0002",
+		Name:    "move_user_role_id_to_user_roles",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn("users", "role_id") {
+				return nil
+			}
+
+			if err := tx.Exec(`INSERT INTO user_roles (user_id, role_id)
+				SELECT users.id, users.role_id FROM users
+				JOIN roles ON roles.id = users.role_id
+				WHERE NOT EXISTS (
+					SELECT 1 FROM user_roles WHERE user_roles.user_id = users.id AND user_roles.role_id = users.role_id
+				)`).Error; err != nil {
+				return err
+			}
+			err := preserveIndexes(tx, "users", func() error {
+				if tx.Migrator().HasConstraint("users", "fk_users_role") {
+					if err := tx.Migrator().DropConstraint("users", "fk_users_role"); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropColumn(&User{}, "RoleID")
+			})
+			if err != nil {
+				return err
+			}
+			log.Println("users.role_id 已迁移至 user_roles")
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			// 恢复 role_id 列，取每个用户的第一个角色
+			if !tx.Migrator().HasColumn("users", "role_id") {
+				if err := tx.Migrator().AddColumn(&User{}, "RoleID"); err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`UPDATE users SET role_id = (
+				SELECT MIN(user_roles.role_id) FROM user_roles WHERE user_roles.user_id = users.id
+			)`).Error
+		},
+	})
+}
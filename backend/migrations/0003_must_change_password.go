@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 用户增加“下次登录需修改密码”标记
+func init() {
+	type User struct {
+		MustChangePassword bool `gorm:"default:false"`
+	}
+
+	register(Migration{
+		Version: "0003",
+		Name:    "add_users_must_change_password",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&User{}, "MustChangePassword") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&User{}, "MustChangePassword")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &User{}, "MustChangePassword")
+		},
+	})
+}
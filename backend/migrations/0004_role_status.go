@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 角色增加状态字段
+func init() {
+	type Role struct {
+		Status int `gorm:"default:1"`
+	}
+
+	register(Migration{
+		Version: "0004",
+		Name:    "add_roles_status",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Role{}, "Status") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Role{}, "Status")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &Role{}, "Status")
+		},
+	})
+}
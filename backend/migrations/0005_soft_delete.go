@@ -0,0 +1,44 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 用户和角色支持软删除
+func init() {
+	type User struct {
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type Role struct {
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	register(Migration{
+		Version: "0005",
+		Name:    "add_users_roles_deleted_at",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&User{}, &Role{}} {
+				if !tx.Migrator().HasColumn(model, "DeletedAt") {
+					if err := tx.Migrator().AddColumn(model, "DeletedAt"); err != nil {
+						return err
+					}
+				}
+				if !tx.Migrator().HasIndex(model, "DeletedAt") {
+					if err := tx.Migrator().CreateIndex(model, "DeletedAt"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&User{}, &Role{}} {
+				if err := tx.Migrator().DropIndex(model, "DeletedAt"); err != nil {
+					return err
+				}
+				if err := dropColumns(tx, model, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 角色增加系统内置标记，内置角色不允许删除
+func init() {
+	type Role struct {
+		IsSystem bool `gorm:"default:false"`
+	}
+
+	register(Migration{
+		Version: "0006",
+		Name:    "add_roles_is_system",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Role{}, "IsSystem") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Role{}, "IsSystem")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &Role{}, "IsSystem")
+		},
+	})
+}
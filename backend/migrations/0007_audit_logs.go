@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 新增审计日志表
+func init() {
+	type AuditLog struct {
+		ID           uint      `gorm:"primarykey"`
+		UserID       uint      `gorm:"index"`
+		Username     string    `gorm:"size:50"`
+		Action       string    `gorm:"size:50;index"`
+		ResourceType string    `gorm:"size:50;index:idx_audit_logs_resource"`
+		ResourceID   uint      `gorm:"index:idx_audit_logs_resource"`
+		Changes      string    `gorm:"type:text"`
+		IP           string    `gorm:"size:64"`
+		UserAgent    string    `gorm:"size:255"`
+		CreatedAt    time.Time `gorm:"index"`
+	}
+
+	register(Migration{
+		Version: "0007",
+		Name:    "create_audit_logs",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&AuditLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&AuditLog{})
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 新增登录历史表，用户增加登录失败计数和锁定时间
+func init() {
+	type LoginLog struct {
+		ID        uint   `gorm:"primarykey"`
+		UserID    uint   `gorm:"index"`
+		Username  string `gorm:"size:50;index"`
+		IP        string `gorm:"size:64;index"`
+		UserAgent string `gorm:"size:255"`
+		Success   bool
+		Reason    string    `gorm:"size:30"`
+		CreatedAt time.Time `gorm:"index"`
+	}
+	type User struct {
+		FailedLoginCount  int `gorm:"default:0"`
+		LastFailedLoginAt *time.Time
+		LockedUntil       *time.Time
+	}
+	columns := []string{"FailedLoginCount", "LastFailedLoginAt", "LockedUntil"}
+
+	register(Migration{
+		Version: "0008",
+		Name:    "add_login_security",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range columns {
+				if !tx.Migrator().HasColumn(&User{}, column) {
+					if err := tx.Migrator().AddColumn(&User{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Migrator().CreateTable(&LoginLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&LoginLog{}); err != nil {
+				return err
+			}
+			return dropColumns(tx, &User{}, columns...)
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 新增密码历史表，用户增加密码修改时间；已有用户的密码修改时间从迁移时开始计算
+func init() {
+	type PasswordHistory struct {
+		ID           uint   `gorm:"primarykey"`
+		UserID       uint   `gorm:"index;not null"`
+		PasswordHash string `gorm:"size:255;not null"`
+		CreatedAt    time.Time
+	}
+	type User struct {
+		ID                uint `gorm:"primarykey"`
+		PasswordChangedAt *time.Time
+	}
+
+	register(Migration{
+		Version: "0009",
+		Name:    "add_password_policy",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&User{}, "PasswordChangedAt") {
+				if err := tx.Migrator().AddColumn(&User{}, "PasswordChangedAt"); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&User{}).Where("password_changed_at IS NULL").
+				Update("password_changed_at", time.Now()).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&PasswordHistory{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&PasswordHistory{}); err != nil {
+				return err
+			}
+			return dropColumns(tx, &User{}, "PasswordChangedAt")
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 新增自助重置密码令牌表
+func init() {
+	type PasswordResetToken struct {
+		ID        uint      `gorm:"primarykey"`
+		UserID    uint      `gorm:"index;not null"`
+		TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+		ExpiresAt time.Time `gorm:"index"`
+		UsedAt    *time.Time
+		CreatedAt time.Time
+	}
+
+	register(Migration{
+		Version: "0010",
+		Name:    "create_password_reset_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&PasswordResetToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&PasswordResetToken{})
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 两步验证：用户增加 TOTP 字段，角色增加强制两步验证标记，新增恢复码和登录挑战表
+func init() {
+	type User struct {
+		MFAEnabled     bool   `gorm:"default:false"`
+		MFASecret      string `gorm:"size:64"`
+		MFALastCounter int64  `gorm:"default:0"`
+	}
+	type Role struct {
+		RequireMFA bool `gorm:"default:false"`
+	}
+	type MFARecoveryCode struct {
+		ID        uint   `gorm:"primarykey"`
+		UserID    uint   `gorm:"index;not null"`
+		CodeHash  string `gorm:"size:64;not null"`
+		UsedAt    *time.Time
+		CreatedAt time.Time
+	}
+	type MFAChallenge struct {
+		ID        uint      `gorm:"primarykey"`
+		UserID    uint      `gorm:"index;not null"`
+		TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+		Attempts  int       `gorm:"default:0"`
+		ExpiresAt time.Time `gorm:"index"`
+		CreatedAt time.Time
+	}
+	userColumns := []string{"MFAEnabled", "MFASecret", "MFALastCounter"}
+
+	register(Migration{
+		Version: "0011",
+		Name:    "add_mfa",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range userColumns {
+				if !tx.Migrator().HasColumn(&User{}, column) {
+					if err := tx.Migrator().AddColumn(&User{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			if !tx.Migrator().HasColumn(&Role{}, "RequireMFA") {
+				if err := tx.Migrator().AddColumn(&Role{}, "RequireMFA"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateTable(&MFARecoveryCode{}, &MFAChallenge{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&MFAChallenge{}, &MFARecoveryCode{}); err != nil {
+				return err
+			}
+			if err := dropColumns(tx, &Role{}, "RequireMFA"); err != nil {
+				return err
+			}
+			return dropColumns(tx, &User{}, userColumns...)
+		},
+	})
+}
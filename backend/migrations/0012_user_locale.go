@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// 用户增加界面语言偏好
+func init() {
+	type User struct {
+		Locale string `gorm:"size:10"`
+	}
+
+	register(Migration{
+		Version: "0012",
+		Name:    "add_users_locale",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&User{}, "Locale") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&User{}, "Locale")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &User{}, "Locale")
+		},
+	})
+}
@@ -0,0 +1,192 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一个版本化的数据库迁移步骤
+type Migration struct {
+	Version string // 版本号，按字典序执行，如 0001
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   string    `gorm:"primarykey;size:32"`
+	Name      string    `gorm:"size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 迁移记录表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+var registry []Migration
+
+// register 注册迁移，由各迁移文件的 init 调用
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// All 返回按版本排序的全部迁移
+func All() []Migration {
+	migrations := make([]Migration, len(registry))
+	copy(migrations, registry)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations
+}
+
+// Up 执行全部未执行的迁移
+func Up(db *gorm.DB) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range All() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移 %s_%s 执行失败: %w", m.Version, m.Name, err)
+		}
+		log.Printf("已执行迁移 %s_%s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Down 回滚最近执行的 steps 个迁移
+func Down(db *gorm.DB, steps int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	migrations := All()
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移 %s_%s 回滚失败: %w", m.Version, m.Name, err)
+		}
+		log.Printf("已回滚迁移 %s_%s", m.Version, m.Name)
+		steps--
+	}
+	return nil
+}
+
+// Status 获取全部迁移的执行状态
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range All() {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// appliedVersions 读取已执行的迁移记录
+func appliedVersions(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// dropColumns 删除模型对应表的列，并保留表上的其余索引
+func dropColumns(tx *gorm.DB, model interface{}, columns ...string) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	return preserveIndexes(tx, stmt.Schema.Table, func() error {
+		for _, column := range columns {
+			if err := tx.Migrator().DropColumn(model, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// preserveIndexes 执行表结构变更后重建丢失的索引；
+// SQLite 删除列或约束时会重建整张表，表上原有的索引随旧表一起被删除
+func preserveIndexes(tx *gorm.DB, table string, change func() error) error {
+	if tx.Dialector.Name() != "sqlite" {
+		return change()
+	}
+
+	var indexes []struct {
+		Name string
+		SQL  string
+	}
+	if err := tx.Raw("SELECT name, sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", table).
+		Scan(&indexes).Error; err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if tx.Migrator().HasIndex(table, index.Name) {
+			continue
+		}
+		if err := tx.Exec(index.SQL).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
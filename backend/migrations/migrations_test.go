@@ -0,0 +1,275 @@
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// migrationEffects 各迁移执行后应存在的表和列，以及应被移除的列
+var migrationEffects = []struct {
+	version string
+	tables  []string
+	columns map[string][]string
+	dropped map[string][]string
+	indexes map[string][]string
+}{
+	{
+		version: "0001",
+		tables:  []string{"users", "roles", "permissions", "user_roles", "role_permissions", "refresh_tokens", "revoked_tokens"},
+		columns: map[string][]string{"users": {"token_version"}},
+		indexes: map[string][]string{"users": {"idx_users_username"}, "roles": {"idx_roles_code"}},
+	},
+	{version: "0002", dropped: map[string][]string{"users": {"role_id"}}},
+	{version: "0003", columns: map[string][]string{"users": {"must_change_password"}}},
+	{version: "0004", columns: map[string][]string{"roles": {"status"}}},
+	{
+		version: "0005",
+		columns: map[string][]string{"users": {"deleted_at"}, "roles": {"deleted_at"}},
+		indexes: map[string][]string{"users": {"idx_users_deleted_at"}, "roles": {"idx_roles_deleted_at"}},
+	},
+	{version: "0006", columns: map[string][]string{"roles": {"is_system"}}},
+	{version: "0007", tables: []string{"audit_logs"}},
+	{
+		version: "0008",
+		tables:  []string{"login_logs"},
+		columns: map[string][]string{"users": {"failed_login_count", "last_failed_login_at", "locked_until"}},
+	},
+	{
+		version: "0009",
+		tables:  []string{"password_histories"},
+		columns: map[string][]string{"users": {"password_changed_at"}},
+	},
+	{version: "0010", tables: []string{"password_reset_tokens"}},
+	{
+		version: "0011",
+		tables:  []string{"mfa_recovery_codes", "mfa_challenges"},
+		columns: map[string][]string{"users": {"mfa_enabled", "mfa_secret", "mfa_last_counter"}, "roles": {"require_mfa"}},
+	},
+	{version: "0012", columns: map[string][]string{"users": {"locale"}}},
+}
+
+// openTestDB 打开独立的内存 SQLite 数据库，作为 MySQL / PostgreSQL 的替身
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接池失败: %v", err)
+	}
+	// 每个连接都是独立的内存数据库，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// createBaselineDB 按基线提交中 AutoMigrate 的模型建表，并写入使用旧版 users.role_id 的数据
+func createBaselineDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	type Permission struct {
+		ID          uint   `gorm:"primarykey"`
+		Name        string `gorm:"size:50;not null"`
+		Code        string `gorm:"uniqueIndex;size:50;not null"`
+		ParentCode  string `gorm:"size:50"`
+		Path        string `gorm:"size:100"`
+		Type        int    `gorm:"default:1"`
+		Sort        int    `gorm:"default:0"`
+		Description string `gorm:"size:255"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+	type Role struct {
+		ID          uint   `gorm:"primarykey"`
+		Name        string `gorm:"size:50;not null"`
+		Code        string `gorm:"uniqueIndex;size:50;not null"`
+		Description string `gorm:"size:255"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+
+		Permissions []Permission `gorm:"many2many:role_permissions"`
+	}
+	type User struct {
+		ID        uint   `gorm:"primarykey"`
+		Username  string `gorm:"uniqueIndex;size:50;not null"`
+		Password  string `gorm:"size:255;not null"`
+		Realname  string `gorm:"size:50"`
+		Email     string `gorm:"size:100"`
+		Phone     string `gorm:"size:20"`
+		Avatar    string `gorm:"size:255"`
+		Status    int    `gorm:"default:1"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+
+		Role   *Role `gorm:"foreignKey:RoleID"`
+		RoleID *uint
+	}
+
+	if err := db.AutoMigrate(&User{}, &Role{}, &Permission{}); err != nil {
+		t.Fatalf("创建基线表结构失败: %v", err)
+	}
+
+	role := Role{Name: "管理员", Code: "admin", Permissions: []Permission{{Name: "用户管理", Code: "system:user"}}}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("写入基线角色失败: %v", err)
+	}
+	user := User{Username: "admin", Password: "hash", RoleID: &role.ID}
+	if err := db.Omit("Role").Create(&user).Error; err != nil {
+		t.Fatalf("写入基线用户失败: %v", err)
+	}
+}
+
+func TestUp(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, db *gorm.DB)
+		check func(t *testing.T, db *gorm.DB)
+	}{
+		{
+			name:  "全新数据库",
+			setup: func(t *testing.T, db *gorm.DB) {},
+		},
+		{
+			name:  "基线提交创建的数据库",
+			setup: createBaselineDB,
+			check: func(t *testing.T, db *gorm.DB) {
+				var count int64
+				if err := db.Table("user_roles").
+					Joins("JOIN users ON users.id = user_roles.user_id").
+					Joins("JOIN roles ON roles.id = user_roles.role_id").
+					Where("users.username = ? AND roles.code = ?", "admin", "admin").
+					Count(&count).Error; err != nil {
+					t.Fatalf("查询用户角色失败: %v", err)
+				}
+				if count != 1 {
+					t.Errorf("users.role_id 应迁移到 user_roles，实际关联数 %d", count)
+				}
+
+				var status int
+				if err := db.Table("roles").Where("code = ?", "admin").Select("status").Scan(&status).Error; err != nil {
+					t.Fatalf("查询角色状态失败: %v", err)
+				}
+				if status != 1 {
+					t.Errorf("已有角色的 status 应默认为 1，实际为 %d", status)
+				}
+
+				if err := db.Table("role_permissions").Count(&count).Error; err != nil {
+					t.Fatalf("查询角色权限失败: %v", err)
+				}
+				if count != 1 {
+					t.Errorf("角色权限关联应保留，实际关联数 %d", count)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			tt.setup(t, db)
+
+			if err := Up(db); err != nil {
+				t.Fatalf("执行迁移失败: %v", err)
+			}
+			// 重复执行不应有任何变化
+			if err := Up(db); err != nil {
+				t.Fatalf("重复执行迁移失败: %v", err)
+			}
+
+			assertApplied(t, db, len(All()))
+			assertSchema(t, db)
+			if tt.check != nil {
+				tt.check(t, db)
+			}
+		})
+	}
+}
+
+func TestDownUp(t *testing.T) {
+	db := openTestDB(t)
+	if err := Up(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	// 逐个回滚后重新执行，每个迁移的 Down 都应能撤销其 Up
+	total := len(All())
+	for applied := total - 1; applied >= 0; applied-- {
+		if err := Down(db, 1); err != nil {
+			t.Fatalf("回滚到 %d 个迁移失败: %v", applied, err)
+		}
+		assertApplied(t, db, applied)
+		// 回滚删除列时不应丢失仍在使用的索引
+		if applied > 0 && !db.Migrator().HasIndex("users", "idx_users_username") {
+			t.Fatalf("回滚到 %d 个迁移后丢失索引 idx_users_username", applied)
+		}
+	}
+	if db.Migrator().HasTable("users") {
+		t.Error("全部回滚后不应存在 users 表")
+	}
+
+	if err := Up(db); err != nil {
+		t.Fatalf("回滚后重新执行迁移失败: %v", err)
+	}
+	assertApplied(t, db, total)
+	assertSchema(t, db)
+}
+
+// assertApplied 校验已执行的迁移数量
+func assertApplied(t *testing.T, db *gorm.DB, want int) {
+	t.Helper()
+
+	statuses, err := Status(db)
+	if err != nil {
+		t.Fatalf("读取迁移状态失败: %v", err)
+	}
+	applied := 0
+	for _, status := range statuses {
+		if status.Applied {
+			applied++
+		}
+	}
+	if applied != want {
+		t.Errorf("已执行迁移数为 %d，期望 %d", applied, want)
+	}
+}
+
+// assertSchema 校验全部迁移执行后的表结构
+func assertSchema(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	for _, effect := range migrationEffects {
+		for _, table := range effect.tables {
+			if !db.Migrator().HasTable(table) {
+				t.Errorf("迁移 %s: 缺少表 %s", effect.version, table)
+			}
+		}
+		for table, columns := range effect.columns {
+			for _, column := range columns {
+				if !db.Migrator().HasColumn(table, column) {
+					t.Errorf("迁移 %s: 表 %s 缺少列 %s", effect.version, table, column)
+				}
+			}
+		}
+		for table, indexes := range effect.indexes {
+			for _, index := range indexes {
+				if !db.Migrator().HasIndex(table, index) {
+					t.Errorf("迁移 %s: 表 %s 缺少索引 %s", effect.version, table, index)
+				}
+			}
+		}
+		for table, columns := range effect.dropped {
+			for _, column := range columns {
+				if db.Migrator().HasColumn(table, column) {
+					t.Errorf("迁移 %s: 表 %s 不应包含列 %s", effect.version, table, column)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditLog 审计日志，记录变更操作的操作人、资源及字段差异
+type AuditLog struct {
+	ID           uint         `gorm:"primarykey" json:"id"`
+	UserID       uint         `gorm:"index" json:"user_id"` // 操作人
+	Username     string       `gorm:"size:50" json:"username"`
+	Action       string       `gorm:"size:50;index" json:"action"`
+	ResourceType string       `gorm:"size:50;index:idx_audit_logs_resource" json:"resource_type"`
+	ResourceID   uint         `gorm:"index:idx_audit_logs_resource" json:"resource_id"`
+	Changes      AuditChanges `gorm:"type:text" json:"changes"`
+	IP           string       `gorm:"size:64" json:"ip"`
+	UserAgent    string       `gorm:"size:255" json:"user_agent"`
+	CreatedAt    time.Time    `gorm:"index" json:"created_at"`
+}
+
+// FieldChange 字段变更前后的值
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges 字段差异，以 JSON 文本存储
+type AuditChanges map[string]FieldChange
+
+// Value 序列化为 JSON 文本
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 从 JSON 文本反序列化
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("无法解析审计差异: %T", value)
+	}
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"react-go-admin-backend/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// OpenDB 按配置的驱动打开数据库连接并设置连接池
+func OpenDB(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := newDialector(dbConfig.Driver, dbConfig.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(gormLogLevel(config.GetLogLevel())),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(dbConfig.ConnMaxLifetimeMinute) * time.Minute)
+
+	return db, nil
+}
+
+// newDialector 根据驱动名称创建 GORM 方言
+func newDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case config.DriverSQLite, "":
+		return sqlite.Open(dsn), nil
+	case config.DriverMySQL:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+}
+
+// gormLogLevel 将配置中的日志级别转换为 GORM 日志级别
+func gormLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "debug":
+		return logger.Info
+	case "error":
+		return logger.Error
+	default:
+		return logger.Warn
+	}
+}
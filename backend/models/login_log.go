@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// 登录结果原因
+const (
+	LoginReasonSuccess      = "success"
+	LoginReasonUserNotFound = "user_not_found"
+	LoginReasonBadPassword  = "bad_password"
+	LoginReasonDisabled     = "disabled"
+	LoginReasonLocked       = "locked"
+	LoginReasonRateLimited  = "rate_limited"
+	LoginReasonMFARequired  = "mfa_required" // 密码正确，等待两步验证
+	LoginReasonMFAFailed    = "mfa_failed"
+)
+
+// LoginLog 登录历史，记录每一次登录尝试
+type LoginLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"` // 用户不存在时为 0
+	Username  string    `gorm:"size:50;index" json:"username"`
+	IP        string    `gorm:"size:64;index" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `gorm:"size:30" json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// MFARecoveryCode 两步验证恢复码，仅保存哈希值，使用一次后失效
+type MFARecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAChallenge 登录第二步验证的挑战令牌，密码验证通过后签发，仅保存哈希值
+type MFAChallenge struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	TokenHash string    `gorm:"uniqueIndex;size:64;not null" json:"-"`
+	Attempts  int       `gorm:"default:0" json:"attempts"` // 验证码错误次数
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
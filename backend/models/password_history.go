@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PasswordHistory 密码历史，保存用户使用过的密码哈希，用于防止重复使用
+type PasswordHistory struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	UserID       uint      `gorm:"index;not null" json:"user_id"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
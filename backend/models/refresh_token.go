@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RefreshToken 刷新令牌，仅保存哈希值；同一次登录派生出的令牌共享 FamilyID
+type RefreshToken struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash  string     `gorm:"uniqueIndex;size:64;not null" json:"-"`
+	FamilyID   string     `gorm:"index;size:32;not null" json:"family_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *uint      `json:"replaced_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RevokedToken 已吊销的访问令牌，过期后可清理
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	JTI       string    `gorm:"uniqueIndex;size:32;not null" json:"jti"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,239 @@
+package models
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+//go:embed seed.yaml
+var defaultSeed []byte
+
+// SeedData 种子数据
+type SeedData struct {
+	Permissions []SeedPermission `yaml:"permissions"`
+	Roles       []SeedRole       `yaml:"roles"`
+}
+
+// SeedPermission 权限种子
+type SeedPermission struct {
+	Code        string `yaml:"code"`
+	Name        string `yaml:"name"`
+	ParentCode  string `yaml:"parent_code"`
+	Path        string `yaml:"path"`
+	Type        int    `yaml:"type"`
+	Sort        int    `yaml:"sort"`
+	Description string `yaml:"description"`
+}
+
+// SeedRole 角色种子
+type SeedRole struct {
+	Code        string   `yaml:"code"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	System      bool     `yaml:"system"`      // 系统内置角色，不允许删除
+	Permissions []string `yaml:"permissions"` // "*" 表示全部权限
+}
+
+// LoadSeed 加载种子数据，path 为空时使用内置数据
+func LoadSeed(path string) (*SeedData, error) {
+	data := defaultSeed
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("读取种子文件失败: %w", err)
+		}
+	}
+
+	var seed SeedData
+	if err := yaml.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("解析种子文件失败: %w", err)
+	}
+	return &seed, nil
+}
+
+// ApplySeed 按 code 幂等写入权限、角色及角色授权，并确保初始管理员存在
+func ApplySeed(seed *SeedData, seedConfig config.SeedConfig) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]Permission, len(seed.Permissions))
+		for _, p := range seed.Permissions {
+			permission := Permission{
+				Name:        p.Name,
+				Code:        p.Code,
+				ParentCode:  p.ParentCode,
+				Path:        p.Path,
+				Type:        p.Type,
+				Sort:        p.Sort,
+				Description: p.Description,
+			}
+			if permission.Type == 0 {
+				permission.Type = 1
+			}
+			if err := upsertByCode(tx, &permission, permission.Code, map[string]interface{}{
+				"name":        permission.Name,
+				"parent_code": permission.ParentCode,
+				"path":        permission.Path,
+				"type":        permission.Type,
+				"sort":        permission.Sort,
+				"description": permission.Description,
+			}); err != nil {
+				return err
+			}
+			permissions[permission.Code] = permission
+		}
+
+		for _, r := range seed.Roles {
+			role := Role{Name: r.Name, Code: r.Code, Description: r.Description, IsSystem: r.System}
+			if err := upsertByCode(tx, &role, role.Code, map[string]interface{}{
+				"name":        role.Name,
+				"description": role.Description,
+				"is_system":   role.IsSystem,
+			}); err != nil {
+				return err
+			}
+			// 已删除的角色不再补充授权
+			if role.DeletedAt.Valid {
+				continue
+			}
+
+			grants, err := resolveGrants(tx, r.Permissions, permissions)
+			if err != nil {
+				return fmt.Errorf("角色 %s: %w", r.Code, err)
+			}
+			if len(grants) > 0 {
+				if err := tx.Model(&role).Association("Permissions").Append(grants); err != nil {
+					return err
+				}
+			}
+		}
+
+		return ensureAdmin(tx, seedConfig)
+	})
+}
+
+// upsertByCode 按 code 查找记录（含已软删除的记录），存在则更新种子维护的字段，否则创建
+func upsertByCode(tx *gorm.DB, model interface{}, code string, updates map[string]interface{}) error {
+	err := tx.Unscoped().Where("code = ?", code).First(model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(model).Error
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(model).Updates(updates).Error
+}
+
+// resolveGrants 将权限代码解析为权限记录
+func resolveGrants(tx *gorm.DB, codes []string, seeded map[string]Permission) ([]Permission, error) {
+	for _, code := range codes {
+		if code == "*" {
+			var all []Permission
+			if err := tx.Find(&all).Error; err != nil {
+				return nil, err
+			}
+			return all, nil
+		}
+	}
+
+	grants := make([]Permission, 0, len(codes))
+	for _, code := range codes {
+		permission, ok := seeded[code]
+		if !ok {
+			if err := tx.Where("code = ?", code).First(&permission).Error; err != nil {
+				return nil, fmt.Errorf("权限不存在: %s", code)
+			}
+		}
+		grants = append(grants, permission)
+	}
+	return grants, nil
+}
+
+// legacyAdminPassword 旧版本为初始管理员设置的默认密码
+const legacyAdminPassword = "123456"
+
+// ensureAdmin 确保初始管理员存在并关联管理员角色；新建时要求首次登录修改密码。
+// 已有管理员（如旧版本创建的）没有管理员角色时补充关联，仍在使用旧默认密码时要求修改密码；已删除的管理员不会被重新创建
+func ensureAdmin(tx *gorm.DB, seedConfig config.SeedConfig) error {
+	var adminRole Role
+	if err := tx.Unscoped().Where("code = ?", seedConfig.AdminRole).First(&adminRole).Error; err != nil {
+		return fmt.Errorf("管理员角色 %s 不存在: %w", seedConfig.AdminRole, err)
+	}
+	// 管理员角色始终为系统内置角色
+	if !adminRole.IsSystem {
+		if err := tx.Unscoped().Model(&adminRole).Update("is_system", true).Error; err != nil {
+			return err
+		}
+	}
+
+	var admin User
+	err := tx.Unscoped().Where("username = ?", seedConfig.AdminUsername).First(&admin).Error
+	if err == nil {
+		if admin.DeletedAt.Valid {
+			return nil
+		}
+		return upgradeAdmin(tx, &admin, &adminRole)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	password := seedConfig.AdminPassword
+	if password == "" {
+		if password, err = utils.RandomToken(8); err != nil {
+			return err
+		}
+		log.Printf("已创建初始管理员 %s，临时密码: %s（首次登录后需修改）", seedConfig.AdminUsername, password)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	admin = User{
+		Username:           seedConfig.AdminUsername,
+		Password:           string(hashedPassword),
+		Realname:           "管理员",
+		Email:              seedConfig.AdminEmail,
+		Status:             1,
+		MustChangePassword: true,
+		PasswordChangedAt:  &now,
+	}
+	if err := tx.Create(&admin).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&admin).Association("Roles").Append(&adminRole)
+}
+
+// upgradeAdmin 为已有的初始管理员补充管理员角色，仍在使用旧默认密码时要求下次登录修改密码
+func upgradeAdmin(tx *gorm.DB, admin *User, adminRole *Role) error {
+	var count int64
+	if err := tx.Table("user_roles").Where("user_id = ? AND role_id = ?", admin.ID, adminRole.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := tx.Model(admin).Association("Roles").Append(adminRole); err != nil {
+			return err
+		}
+		log.Printf("已为管理员 %s 关联角色 %s", admin.Username, adminRole.Code)
+	}
+
+	if !admin.MustChangePassword && bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(legacyAdminPassword)) == nil {
+		if err := tx.Model(admin).Update("must_change_password", true).Error; err != nil {
+			return err
+		}
+		log.Printf("管理员 %s 仍在使用默认密码，下次登录时需修改", admin.Username)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"testing"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/migrations"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupSeedDB 使用执行过全部迁移的内存 SQLite 数据库替换 DB，测试结束后恢复
+func setupSeedDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接池失败: %v", err)
+	}
+	// 每个连接都是独立的内存数据库，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = previous
+		sqlDB.Close()
+	})
+}
+
+func TestApplySeed(t *testing.T) {
+	seedConfig := config.SeedConfig{AdminUsername: "admin", AdminPassword: "Init#Pass123", AdminRole: "admin"}
+	seed := &SeedData{
+		Permissions: []SeedPermission{
+			{Code: "system", Name: "系统管理"},
+			{Code: "system:user", Name: "用户管理", ParentCode: "system"},
+		},
+		Roles: []SeedRole{
+			{Code: "admin", Name: "管理员", System: true, Permissions: []string{"*"}},
+			{Code: "viewer", Name: "访客", Permissions: []string{"system"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T)
+		check  func(t *testing.T)
+	}{
+		{
+			name: "同步种子维护的权限字段",
+			change: func(t *testing.T) {
+				mustExec(t, DB.Model(&Permission{}).Where("code = ?", "system:user").
+					Updates(map[string]interface{}{"name": "账号管理", "sort": 9, "parent_code": ""}))
+				seed.Permissions[1].Path = "/system/account"
+				t.Cleanup(func() { seed.Permissions[1].Path = "" })
+			},
+			check: func(t *testing.T) {
+				var permission Permission
+				mustExec(t, DB.Where("code = ?", "system:user").First(&permission))
+				if permission.Name != "用户管理" || permission.Sort != 0 || permission.ParentCode != "system" || permission.Path != "/system/account" {
+					t.Errorf("权限未按种子更新: %+v", permission)
+				}
+			},
+		},
+		{
+			name: "同步种子维护的角色字段",
+			change: func(t *testing.T) {
+				mustExec(t, DB.Model(&Role{}).Where("code = ?", "viewer").
+					Updates(map[string]interface{}{"name": "只读用户", "is_system": true, "status": 0}))
+			},
+			check: func(t *testing.T) {
+				var role Role
+				mustExec(t, DB.Where("code = ?", "viewer").First(&role))
+				if role.Name != "访客" || role.IsSystem {
+					t.Errorf("角色未按种子更新: %+v", role)
+				}
+				if role.Status != 0 {
+					t.Error("种子不维护的角色状态被覆盖")
+				}
+			},
+		},
+		{
+			name: "补回缺失的授权并保留后台额外分配的授权",
+			change: func(t *testing.T) {
+				var role Role
+				mustExec(t, DB.Where("code = ?", "viewer").First(&role))
+				var extra Permission
+				mustExec(t, DB.Where("code = ?", "system:user").First(&extra))
+				if err := DB.Model(&role).Association("Permissions").Replace([]Permission{extra}); err != nil {
+					t.Fatalf("修改授权失败: %v", err)
+				}
+			},
+			check: func(t *testing.T) {
+				if codes := rolePermissionCodes(t, "viewer"); len(codes) != 2 {
+					t.Errorf("访客角色的授权为 %v，期望包含种子授权及后台分配的授权", codes)
+				}
+			},
+		},
+		{
+			name: "管理员没有管理员角色时重新关联",
+			change: func(t *testing.T) {
+				var admin User
+				mustExec(t, DB.Where("username = ?", "admin").First(&admin))
+				if err := DB.Model(&admin).Association("Roles").Clear(); err != nil {
+					t.Fatalf("移除角色失败: %v", err)
+				}
+			},
+			check: func(t *testing.T) {
+				var count int64
+				mustExec(t, DB.Table("user_roles").Count(&count))
+				if count != 1 {
+					t.Errorf("管理员角色关联数为 %d，期望 1", count)
+				}
+			},
+		},
+		{
+			name: "不为已删除的管理员授予角色",
+			change: func(t *testing.T) {
+				var admin User
+				mustExec(t, DB.Where("username = ?", "admin").First(&admin))
+				if err := DB.Model(&admin).Association("Roles").Clear(); err != nil {
+					t.Fatalf("移除角色失败: %v", err)
+				}
+				mustExec(t, DB.Delete(&admin))
+			},
+			check: func(t *testing.T) {
+				var count int64
+				mustExec(t, DB.Unscoped().Model(&User{}).Where("username = ?", "admin").Count(&count))
+				if count != 1 {
+					t.Errorf("管理员记录数为 %d，已删除的管理员不应被重新创建", count)
+				}
+				mustExec(t, DB.Table("user_roles").Count(&count))
+				if count != 0 {
+					t.Errorf("已删除的管理员被授予角色，关联数 %d", count)
+				}
+			},
+		},
+		{
+			name: "新增权限授予种子中声明的已有角色",
+			change: func(t *testing.T) {
+				seed.Permissions = append(seed.Permissions, SeedPermission{Code: "system:audit", Name: "审计日志", ParentCode: "system"})
+				seed.Roles[1].Permissions = []string{"system", "system:audit"}
+				t.Cleanup(func() {
+					seed.Permissions = seed.Permissions[:2]
+					seed.Roles[1].Permissions = []string{"system"}
+				})
+			},
+			check: func(t *testing.T) {
+				if codes := rolePermissionCodes(t, "admin"); len(codes) != 3 {
+					t.Errorf("管理员角色的授权为 %v，应包含新增权限", codes)
+				}
+				if codes := rolePermissionCodes(t, "viewer"); len(codes) != 2 {
+					t.Errorf("访客角色的授权为 %v，应包含新增权限", codes)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupSeedDB(t)
+			if err := ApplySeed(seed, seedConfig); err != nil {
+				t.Fatalf("首次写入种子失败: %v", err)
+			}
+			tt.change(t)
+			if err := ApplySeed(seed, seedConfig); err != nil {
+				t.Fatalf("再次写入种子失败: %v", err)
+			}
+			tt.check(t)
+		})
+	}
+}
+
+// mustExec 查询或更新失败时终止测试
+func mustExec(t *testing.T, result *gorm.DB) {
+	t.Helper()
+
+	if result.Error != nil {
+		t.Fatalf("数据库操作失败: %v", result.Error)
+	}
+}
+
+// rolePermissionCodes 返回角色已授权的权限代码
+func rolePermissionCodes(t *testing.T, roleCode string) []string {
+	t.Helper()
+
+	var codes []string
+	mustExec(t, DB.Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.code = ?", roleCode).
+		Pluck("permissions.code", &codes))
+	return codes
+}
+
+func TestApplySeedBaselineAdmin(t *testing.T) {
+	seedConfig := config.SeedConfig{AdminUsername: "admin", AdminPassword: "Init#Pass123", AdminRole: "admin"}
+	seed := &SeedData{
+		Permissions: []SeedPermission{{Code: "system", Name: "系统管理"}},
+		Roles:       []SeedRole{{Code: "admin", Name: "超级管理员", System: true, Permissions: []string{"*"}}},
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		mustChange bool
+	}{
+		{name: "仍在使用旧默认密码", password: legacyAdminPassword, mustChange: true},
+		{name: "已修改过密码", password: "Changed#Pass456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupSeedDB(t)
+			// 旧版本的数据：管理员未关联任何角色，角色不是系统角色，密码无需修改
+			hash, err := bcrypt.GenerateFromPassword([]byte(tt.password), bcrypt.MinCost)
+			if err != nil {
+				t.Fatalf("生成密码失败: %v", err)
+			}
+			mustExec(t, DB.Create(&User{Username: "admin", Password: string(hash), Realname: "管理员", Status: 1}))
+			mustExec(t, DB.Create(&[]Role{{Name: "超级管理员", Code: "admin"}, {Name: "普通用户", Code: "user"}}))
+
+			if err := ApplySeed(seed, seedConfig); err != nil {
+				t.Fatalf("写入种子失败: %v", err)
+			}
+
+			var admin User
+			mustExec(t, DB.Preload("Roles").Where("username = ?", "admin").First(&admin))
+			if len(admin.Roles) != 1 || admin.Roles[0].Code != "admin" || !admin.Roles[0].IsSystem {
+				t.Errorf("管理员的角色为 %+v，期望关联系统角色 admin", admin.Roles)
+			}
+			if admin.MustChangePassword != tt.mustChange {
+				t.Errorf("must_change_password 为 %v，期望 %v", admin.MustChangePassword, tt.mustChange)
+			}
+			if bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(tt.password)) != nil {
+				t.Error("已有管理员的密码被修改")
+			}
+		})
+	}
+}
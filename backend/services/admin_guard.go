@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOperateSelf 不能对自己的账号执行删除、禁用或移除管理员角色等操作
+	ErrOperateSelf = forbidden("operate_self")
+	// ErrLastAdmin 不能删除、禁用最后一个有效管理员或移除其管理员角色
+	ErrLastAdmin = forbidden("last_admin")
+	// ErrLastRoleManager 操作后将没有可管理角色的有效用户
+	ErrLastRoleManager = forbidden("last_role_manager")
+)
+
+// roleManagerPermission 管理角色所需的权限，禁用角色或移除授权后至少保留一个拥有该权限的有效用户
+const roleManagerPermission = "system:role:edit"
+
+// adminGuard 跟踪操作过程中剩余的有效管理员，防止移除最后一个管理员
+type adminGuard struct {
+	roleID uint
+	admins map[uint]bool
+}
+
+// newAdminGuard 加载管理员角色下的全部启用用户，管理员角色被禁用时视为没有有效管理员
+func newAdminGuard(tx *gorm.DB) (*adminGuard, error) {
+	guard := &adminGuard{admins: map[uint]bool{}}
+
+	var role models.Role
+	err := tx.Where("code = ? AND status = ?", config.GetSeed().AdminRole, 1).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return guard, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	guard.roleID = role.ID
+
+	var ids []uint
+	if err := tx.Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Where("user_roles.role_id = ? AND users.status = ?", role.ID, 1).
+		Pluck("users.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		guard.admins[id] = true
+	}
+	return guard, nil
+}
+
+// remove 标记用户不再是有效管理员，若其为最后一个管理员则返回错误
+func (g *adminGuard) remove(userID uint) error {
+	if !g.admins[userID] {
+		return nil
+	}
+	if len(g.admins) <= 1 {
+		return ErrLastAdmin
+	}
+	delete(g.admins, userID)
+	return nil
+}
+
+// removeAdminRole 用户的角色将不再包含管理员角色时校验：操作者不能移除自己的管理员身份，也不能移除最后一个管理员
+func (g *adminGuard) removeAdminRole(operatorID, userID uint) error {
+	if operatorID == userID && g.admins[userID] {
+		return ErrOperateSelf
+	}
+	return g.remove(userID)
+}
+
+// guardUserRemoval 删除或禁用单个用户前校验：不能操作自己的账号，也不能移除最后一个管理员
+func guardUserRemoval(tx *gorm.DB, operatorID, userID uint) error {
+	if operatorID == userID {
+		return ErrOperateSelf
+	}
+	guard, err := newAdminGuard(tx)
+	if err != nil {
+		return err
+	}
+	return guard.remove(userID)
+}
+
+// countRoleManagers 统计通过启用的角色拥有角色编辑权限的启用用户数
+func countRoleManagers(tx *gorm.DB) (int64, error) {
+	var count int64
+	err := tx.Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("users.status = ? AND roles.status = ? AND roles.deleted_at IS NULL AND permissions.code = ?", 1, 1, roleManagerPermission).
+		Distinct("users.id").
+		Count(&count).Error
+	return count, err
+}
+
+// guardRoleManagers 在事务内执行 change，执行前存在可管理角色的有效用户而执行后不再存在时返回错误，由事务回滚修改
+func guardRoleManagers(tx *gorm.DB, change func() error) error {
+	before, err := countRoleManagers(tx)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	if before == 0 {
+		return nil
+	}
+
+	after, err := countRoleManagers(tx)
+	if err != nil {
+		return err
+	}
+	if after == 0 {
+		return ErrLastRoleManager
+	}
+	return nil
+}
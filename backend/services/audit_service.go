@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 审计资源类型
+const (
+	AuditResourceUser       = "user"
+	AuditResourceRole       = "role"
+	AuditResourcePermission = "permission"
+)
+
+// auditColumns 各资源快照包含的字段白名单，未列出的资源记录全部字段；
+// 用户表包含密钥、令牌版本、登录失败计数等敏感或内部字段，新增字段默认不进入审计日志
+var auditColumns = map[string][]string{
+	AuditResourceUser: {
+		"id", "username", "password", "realname", "email", "phone", "avatar", "status",
+		"must_change_password", "mfa_enabled", "locale", "created_at", "updated_at", "deleted_at",
+	},
+}
+
+// auditRedacted 记录差异时脱敏的字段，仅记录是否发生变化
+var auditRedacted = map[string]bool{
+	"password":           true,
+	"mfa_secret":         true,
+	"mfa_last_counter":   true,
+	"token_version":      true,
+	"failed_login_count": true,
+	"locked_until":       true,
+}
+
+// auditIgnored 不参与差异比较的字段
+var auditIgnored = map[string]bool{
+	"updated_at": true,
+}
+
+// auditSortable 审计日志可排序字段
+var auditSortable = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"created_at": "created_at",
+}
+
+// AuditService 审计日志服务
+type AuditService struct{}
+
+// AuditLogQuery 审计日志查询参数
+type AuditLogQuery struct {
+	ListQuery
+	UserID       uint
+	Action       string
+	ResourceType string
+	ResourceID   uint
+}
+
+// Snapshot 获取资源当前的字段快照（含关联 ID），资源不存在时返回 nil
+func (s *AuditService) Snapshot(resourceType string, id uint) (map[string]interface{}, error) {
+	var model interface{}
+	switch resourceType {
+	case AuditResourceUser:
+		model = &models.User{}
+	case AuditResourceRole:
+		model = &models.Role{}
+	case AuditResourcePermission:
+		model = &models.Permission{}
+	default:
+		return nil, fmt.Errorf("未知的审计资源类型: %s", resourceType)
+	}
+
+	db := models.DB.Unscoped().Model(model)
+	if columns, ok := auditColumns[resourceType]; ok {
+		db = db.Select(columns)
+	}
+
+	row := make(map[string]interface{})
+	if err := db.Where("id = ?", id).Take(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for key, value := range row {
+		if b, ok := value.([]byte); ok {
+			row[key] = string(b)
+		}
+	}
+
+	// 关联关系的变更同样需要记录
+	switch resourceType {
+	case AuditResourceUser:
+		var roleIDs []uint
+		if err := models.DB.Table("user_roles").Where("user_id = ?", id).Order("role_id").Pluck("role_id", &roleIDs).Error; err != nil {
+			return nil, err
+		}
+		row["role_ids"] = roleIDs
+	case AuditResourceRole:
+		var permissionIDs []uint
+		if err := models.DB.Table("role_permissions").Where("role_id = ?", id).Order("permission_id").Pluck("permission_id", &permissionIDs).Error; err != nil {
+			return nil, err
+		}
+		row["permission_ids"] = permissionIDs
+	}
+	return row, nil
+}
+
+// Diff 比较前后快照，返回发生变化的字段，敏感字段的值会被脱敏
+func (s *AuditService) Diff(before, after map[string]interface{}) models.AuditChanges {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	changes := make(models.AuditChanges)
+	for key := range keys {
+		if auditIgnored[key] {
+			continue
+		}
+		oldValue, newValue := before[key], after[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if auditRedacted[key] {
+			oldValue, newValue = redact(oldValue), redact(newValue)
+		}
+		changes[key] = models.FieldChange{Before: oldValue, After: newValue}
+	}
+	return changes
+}
+
+// redact 脱敏字段值，保留是否为空的信息
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return "******"
+}
+
+// Record 写入审计日志
+func (s *AuditService) Record(log *models.AuditLog) error {
+	if len(log.UserAgent) > 255 {
+		log.UserAgent = log.UserAgent[:255]
+	}
+	return models.DB.Create(log).Error
+}
+
+// GetAuditLogList 获取审计日志列表，默认按时间倒序
+func (s *AuditService) GetAuditLogList(query AuditLogQuery) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	query.Normalize()
+	query.Status = nil
+	if query.SortBy == "" {
+		query.SortBy, query.SortOrder = "id", "desc"
+	}
+
+	db := query.applyFilters(models.DB.Model(&models.AuditLog{}), "audit_logs", "username", "action", "resource_type")
+	if query.UserID != 0 {
+		db = db.Where("audit_logs.user_id = ?", query.UserID)
+	}
+	if query.Action != "" {
+		db = db.Where("audit_logs.action = ?", query.Action)
+	}
+	if query.ResourceType != "" {
+		db = db.Where("audit_logs.resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceID != 0 {
+		db = db.Where("audit_logs.resource_id = ?", query.ResourceID)
+	}
+
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.applyOrder(db, "audit_logs", auditSortable).
+		Offset(query.Offset()).Limit(query.PageSize).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"strings"
+
+	"react-go-admin-backend/i18n"
+	"react-go-admin-backend/utils"
+)
+
+// 业务错误类型，由 api 层统一转换为 HTTP 状态码及响应：
+// NotFoundError → 404，ConflictError → 409，ValidationError → 400，ForbiddenError → 403，
+// UnauthorizedError → 401，LockedError → 423，RateLimitError → 429，
+// 其他错误视为内部错误，返回 500 且不暴露错误详情。
+// Key 为稳定的错误键，供前端识别错误类型，不随提示文案变化，同时作为消息目录的键，Args 为消息参数；
+// Msg 为默认语言的消息，api 层按请求语言重新翻译。
+
+// NotFoundError 资源不存在
+type NotFoundError struct {
+	Key  string
+	Msg  string
+	Args []interface{}
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Msg
+}
+
+// ForbiddenError 业务规则不允许的操作
+type ForbiddenError struct {
+	Key  string
+	Msg  string
+	Args []interface{}
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Msg
+}
+
+// UnauthorizedError 身份凭据或令牌无效
+type UnauthorizedError struct {
+	Key  string
+	Msg  string
+	Args []interface{}
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Msg
+}
+
+// LockedError 账号被临时锁定
+type LockedError struct {
+	Key  string
+	Msg  string
+	Args []interface{}
+}
+
+func (e *LockedError) Error() string {
+	return e.Msg
+}
+
+// RateLimitError 请求过于频繁
+type RateLimitError struct {
+	Key  string
+	Msg  string
+	Args []interface{}
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Msg
+}
+
+// ConflictError 与现有数据冲突（唯一性、引用关系或状态）而拒绝操作，Details 附带受影响的记录
+type ConflictError struct {
+	Key     string
+	Msg     string
+	Args    []interface{}
+	Details map[string]interface{}
+}
+
+func (e *ConflictError) Error() string {
+	return e.Msg
+}
+
+// ValidationError 字段级校验错误
+type ValidationError struct {
+	Fields []utils.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// notFound 创建资源不存在错误
+func notFound(key string, args ...interface{}) *NotFoundError {
+	return &NotFoundError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// forbidden 创建业务规则禁止错误
+func forbidden(key string, args ...interface{}) *ForbiddenError {
+	return &ForbiddenError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// unauthorized 创建凭据无效错误
+func unauthorized(key string, args ...interface{}) *UnauthorizedError {
+	return &UnauthorizedError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// locked 创建账号锁定错误
+func locked(key string, args ...interface{}) *LockedError {
+	return &LockedError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// rateLimited 创建请求过于频繁错误
+func rateLimited(key string, args ...interface{}) *RateLimitError {
+	return &RateLimitError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// conflict 创建数据冲突错误
+func conflict(key string, args ...interface{}) *ConflictError {
+	return &ConflictError{Key: key, Msg: i18n.T(i18n.DefaultLocale, key, args...), Args: args}
+}
+
+// fieldError 创建字段错误，key 为消息目录中的键
+func fieldError(field, rule, key string, args ...interface{}) utils.FieldError {
+	return utils.FieldError{
+		Field:   field,
+		Rule:    rule,
+		Message: i18n.T(i18n.DefaultLocale, key, args...),
+		Key:     key,
+		Args:    args,
+	}
+}
+
+// invalid 创建单个字段的校验错误
+func invalid(field, rule, key string, args ...interface{}) *ValidationError {
+	return &ValidationError{Fields: []utils.FieldError{fieldError(field, rule, key, args...)}}
+}
+
+// RoleUserRef 引用角色的用户
+type RoleUserRef struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Realname string `json:"realname"`
+}
+
+// PermissionRoleRef 引用权限的角色
+type PermissionRoleRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
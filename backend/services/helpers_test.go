@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/migrations"
+	"react-go-admin-backend/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeClock 测试用时钟，时间只在调用 Advance 时前进
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)}
+}
+
+// Now 返回当前的模拟时间
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// Advance 将模拟时间向前推进 d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// setupTestDB 使用执行过全部迁移的内存 SQLite 数据库替换 models.DB，测试结束后恢复
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接池失败: %v", err)
+	}
+	// 每个连接都是独立的内存数据库，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	previous := models.DB
+	models.DB = db
+	t.Cleanup(func() {
+		models.DB = previous
+		sqlDB.Close()
+	})
+}
+
+// setSecurity 修改测试期间的安全配置，测试结束后恢复
+func setSecurity(t *testing.T, change func(security *config.SecurityConfig)) {
+	t.Helper()
+
+	security := &config.Get().Security
+	previous := *security
+	change(security)
+	t.Cleanup(func() { *security = previous })
+}
+
+// createTestUser 创建启用状态的测试用户
+func createTestUser(t *testing.T, clock *fakeClock, username, password string) *models.User {
+	t.Helper()
+
+	user := &models.User{Username: username, Password: password, Status: 1}
+	if err := (&UserService{Clock: clock}).CreateUser(user); err != nil {
+		t.Fatalf("创建用户 %s 失败: %v", username, err)
+	}
+	return user
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// dummyPasswordHash 用户不存在时用于比较的固定哈希，与真实密码使用相同的 bcrypt 成本，
+// 使两种情况的响应时间一致，避免通过耗时判断用户名是否存在
+const dummyPasswordHash = "$2a$10$sSA9OWWlYumNb/yo2e1.gOT6BPRn1VcE3YHClJM6zk8mnkCu9S4H6"
+
+var (
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = unauthorized("invalid_credentials")
+	// ErrAccountDisabled 账号已被禁用
+	ErrAccountDisabled = forbidden("account_disabled")
+	// ErrAccountLocked 登录失败次数过多，账号被临时锁定
+	ErrAccountLocked = locked("account_locked")
+	// ErrLoginRateLimited 同一 IP 登录尝试过于频繁
+	ErrLoginRateLimited = rateLimited("login_rate_limited")
+)
+
+// LoginService 登录服务：校验凭据、记录登录历史并防止暴力破解
+type LoginService struct {
+	Clock utils.Clock // 为空时使用系统时钟
+}
+
+// LoginAttempt 登录请求来源信息
+type LoginAttempt struct {
+	Username  string
+	Password  string
+	IP        string
+	UserAgent string
+}
+
+// LoginLogQuery 登录历史查询参数
+type LoginLogQuery struct {
+	ListQuery
+	UserID  uint
+	IP      string
+	Success *bool
+	Reason  string
+}
+
+// credentialFailureReasons 计入 IP 登录限制的失败原因
+var credentialFailureReasons = []string{
+	models.LoginReasonUserNotFound,
+	models.LoginReasonBadPassword,
+	models.LoginReasonMFAFailed,
+}
+
+// loginLogSortable 登录历史可排序字段
+var loginLogSortable = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"created_at": "created_at",
+}
+
+func (s *LoginService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock.Now()
+	}
+	return time.Now()
+}
+
+// Authenticate 校验登录凭据，每次尝试都会记录登录历史。
+// 同一 IP 在窗口内尝试过多时拒绝登录；账号在窗口内连续失败达到上限时临时锁定。
+func (s *LoginService) Authenticate(attempt LoginAttempt) (*models.User, error) {
+	security := config.GetSecurity()
+	userService := &UserService{Clock: s.Clock}
+	now := s.now()
+
+	// 仅统计凭据错误，成功登录及被拒绝的尝试不延长限制时间
+	var recent int64
+	if err := models.DB.Model(&models.LoginLog{}).
+		Where("ip = ? AND created_at >= ? AND reason IN ?", attempt.IP,
+			now.Add(-time.Duration(security.LoginIPWindowMinute)*time.Minute), credentialFailureReasons).
+		Count(&recent).Error; err != nil {
+		return nil, err
+	}
+	if recent >= int64(security.LoginIPMaxAttempts) {
+		s.record(attempt, nil, models.LoginReasonRateLimited)
+		return nil, ErrLoginRateLimited
+	}
+
+	user, err := userService.GetUserByUsername(attempt.Username)
+	if err != nil {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(attempt.Password))
+		s.record(attempt, nil, models.LoginReasonUserNotFound)
+		return nil, ErrInvalidCredentials
+	}
+
+	// 锁定期间不校验密码
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		s.record(attempt, user, models.LoginReasonLocked)
+		return nil, ErrAccountLocked
+	}
+
+	if !userService.VerifyPassword(user, attempt.Password) {
+		locked, err := s.registerFailure(user, now, security)
+		if err != nil {
+			return nil, err
+		}
+		s.record(attempt, user, models.LoginReasonBadPassword)
+		if locked {
+			return nil, ErrAccountLocked
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Status != 1 {
+		s.record(attempt, user, models.LoginReasonDisabled)
+		return nil, ErrAccountDisabled
+	}
+
+	if user.FailedLoginCount > 0 || user.LockedUntil != nil {
+		if err := s.resetFailures(models.DB, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 密码超过最长使用期限时要求修改密码
+	if !user.MustChangePassword && passwordExpired(user, now) {
+		if err := models.DB.Model(user).Update("must_change_password", true).Error; err != nil {
+			return nil, err
+		}
+	}
+	if user.MFAEnabled {
+		s.record(attempt, user, models.LoginReasonMFARequired)
+	} else {
+		s.record(attempt, user, models.LoginReasonSuccess)
+	}
+	return user, nil
+}
+
+// VerifyMFA 登录第二步：校验挑战令牌及验证码，验证码错误计入账号登录失败次数
+func (s *LoginService) VerifyMFA(attempt LoginAttempt, token, code string) (*models.User, error) {
+	now := s.now()
+	user, err := (&MFAService{Clock: s.Clock}).VerifyChallenge(token, code)
+	if errors.Is(err, ErrMFACodeInvalid) {
+		attempt.Username = user.Username
+		locked, err := s.registerFailure(user, now, config.GetSecurity())
+		if err != nil {
+			return nil, err
+		}
+		s.record(attempt, user, models.LoginReasonMFAFailed)
+		if locked {
+			return nil, ErrAccountLocked
+		}
+		return nil, ErrMFACodeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.Username = user.Username
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		s.record(attempt, user, models.LoginReasonLocked)
+		return nil, ErrAccountLocked
+	}
+	if user.Status != 1 {
+		s.record(attempt, user, models.LoginReasonDisabled)
+		return nil, ErrAccountDisabled
+	}
+
+	if user.FailedLoginCount > 0 {
+		if err := s.resetFailures(models.DB, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	s.record(attempt, user, models.LoginReasonSuccess)
+	return user, nil
+}
+
+// registerFailure 原子地累计登录失败次数，超出统计窗口的失败重新计数；达到上限时锁定账号并返回 true
+func (s *LoginService) registerFailure(user *models.User, now time.Time, security config.SecurityConfig) (bool, error) {
+	window := time.Duration(security.LoginFailureWindowMinute) * time.Minute
+
+	var locked bool
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		// 在数据库中递增，避免并发失败时读-改-写丢失计数；
+		// failed_login_count 须先于 last_failed_login_at 赋值，MySQL 按顺序使用已更新的列值
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"failed_login_count": gorm.Expr(
+				"CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE failed_login_count + 1 END",
+				now.Add(-window)),
+			"last_failed_login_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var current models.User
+		if err := tx.Select("failed_login_count").First(&current, user.ID).Error; err != nil {
+			return err
+		}
+		if current.FailedLoginCount < security.LoginMaxFailures {
+			return nil
+		}
+
+		locked = true
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"failed_login_count": 0,
+			"locked_until":       now.Add(time.Duration(security.LoginLockoutMinute) * time.Minute),
+		}).Error
+	})
+	return locked, err
+}
+
+// resetFailures 清除登录失败计数和锁定状态
+func (s *LoginService) resetFailures(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+	}).Error
+}
+
+// record 写入登录历史，写入失败不影响登录流程
+func (s *LoginService) record(attempt LoginAttempt, user *models.User, reason string) {
+	entry := models.LoginLog{
+		Username:  attempt.Username,
+		IP:        attempt.IP,
+		UserAgent: attempt.UserAgent,
+		Success:   reason == models.LoginReasonSuccess,
+		Reason:    reason,
+		CreatedAt: s.now(),
+	}
+	if user != nil {
+		entry.UserID = user.ID
+	}
+	if len(entry.Username) > 50 {
+		entry.Username = entry.Username[:50]
+	}
+	if len(entry.UserAgent) > 255 {
+		entry.UserAgent = entry.UserAgent[:255]
+	}
+	models.DB.Create(&entry)
+}
+
+// UnlockUser 解除账号锁定并清除登录失败计数
+func (s *LoginService) UnlockUser(userID uint) error {
+	if _, err := (&UserService{}).GetUserByID(userID); err != nil {
+		return err
+	}
+	return s.resetFailures(models.DB, userID)
+}
+
+// GetLoginLogList 获取登录历史，默认按时间倒序
+func (s *LoginService) GetLoginLogList(query LoginLogQuery) ([]models.LoginLog, int64, error) {
+	var logs []models.LoginLog
+	var total int64
+
+	query.Normalize()
+	query.Status = nil
+	if query.SortBy == "" {
+		query.SortBy, query.SortOrder = "id", "desc"
+	}
+
+	db := query.applyFilters(models.DB.Model(&models.LoginLog{}), "login_logs", "username", "ip")
+	if query.UserID != 0 {
+		db = db.Where("login_logs.user_id = ?", query.UserID)
+	}
+	if query.IP != "" {
+		db = db.Where("login_logs.ip = ?", query.IP)
+	}
+	if query.Success != nil {
+		db = db.Where("login_logs.success = ?", *query.Success)
+	}
+	if query.Reason != "" {
+		db = db.Where("login_logs.reason = ?", query.Reason)
+	}
+
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.applyOrder(db, "login_logs", loginLogSortable).
+		Offset(query.Offset()).Limit(query.PageSize).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
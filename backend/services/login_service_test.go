@@ -0,0 +1,256 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testPassword = "Quick#Fox789"
+	testIP       = "203.0.113.10"
+)
+
+// loginStep 一次登录尝试：先推进时间，再校验返回的错误
+type loginStep struct {
+	advance  time.Duration
+	username string
+	password string
+	ip       string
+	want     error
+}
+
+func TestLoginServiceAuthenticate(t *testing.T) {
+	good := func(advance time.Duration, want error) loginStep {
+		return loginStep{advance: advance, username: "alice", password: testPassword, ip: testIP, want: want}
+	}
+	bad := func(advance time.Duration, want error) loginStep {
+		return loginStep{advance: advance, username: "alice", password: "Wrong#Pass000", ip: testIP, want: want}
+	}
+	fromIP := func(ip string, step loginStep) loginStep {
+		step.ip = ip
+		return step
+	}
+	unknown := func(ip string, want error) loginStep {
+		return loginStep{username: "nobody", password: testPassword, ip: ip, want: want}
+	}
+
+	tests := []struct {
+		name  string
+		steps []loginStep
+		check func(t *testing.T, clock *fakeClock)
+	}{
+		{
+			name:  "密码正确",
+			steps: []loginStep{good(0, nil)},
+		},
+		{
+			name: "连续失败达到上限后锁定",
+			steps: []loginStep{
+				bad(0, ErrInvalidCredentials),
+				bad(time.Minute, ErrInvalidCredentials),
+				bad(time.Minute, ErrAccountLocked),
+				// 锁定期间密码正确也拒绝登录
+				good(time.Minute, ErrAccountLocked),
+				good(13*time.Minute, ErrAccountLocked),
+				// 锁定到期后恢复
+				good(time.Minute, nil),
+			},
+			check: func(t *testing.T, clock *fakeClock) {
+				user := reloadUser(t, "alice")
+				if user.FailedLoginCount != 0 || user.LockedUntil != nil {
+					t.Errorf("登录成功后应清除失败计数和锁定，实际计数 %d，锁定至 %v", user.FailedLoginCount, user.LockedUntil)
+				}
+			},
+		},
+		{
+			name: "锁定到期前后的边界",
+			steps: []loginStep{
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrAccountLocked),
+				good(15*time.Minute-time.Second, ErrAccountLocked),
+				good(time.Second, nil),
+			},
+		},
+		{
+			name: "超出统计窗口的失败重新计数",
+			steps: []loginStep{
+				bad(0, ErrInvalidCredentials),
+				bad(time.Minute, ErrInvalidCredentials),
+				bad(11*time.Minute, ErrInvalidCredentials),
+				bad(time.Minute, ErrInvalidCredentials),
+				bad(time.Minute, ErrAccountLocked),
+			},
+		},
+		{
+			name: "登录成功清除失败计数",
+			steps: []loginStep{
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrInvalidCredentials),
+				good(0, nil),
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrInvalidCredentials),
+			},
+		},
+		{
+			name: "同一 IP 凭据错误过多时限制登录",
+			steps: []loginStep{
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				good(0, ErrLoginRateLimited),
+				// 其他 IP 不受影响
+				fromIP("198.51.100.7", good(0, nil)),
+			},
+		},
+		{
+			name: "被限制的尝试不延长 IP 限制时间",
+			steps: []loginStep{
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrInvalidCredentials),
+				good(30*time.Second, ErrLoginRateLimited),
+				good(20*time.Second, ErrLoginRateLimited),
+				good(10*time.Second+time.Millisecond, nil),
+			},
+		},
+		{
+			name: "成功登录不计入 IP 限制",
+			steps: []loginStep{
+				good(0, nil),
+				good(0, nil),
+				good(0, nil),
+				good(0, nil),
+				good(0, nil),
+				good(0, nil),
+			},
+		},
+		{
+			name: "账号锁定不计入 IP 限制",
+			steps: []loginStep{
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrInvalidCredentials),
+				bad(0, ErrAccountLocked),
+				good(0, ErrAccountLocked),
+				good(0, ErrAccountLocked),
+				// 窗口内仅 3 次凭据错误，未达到 IP 上限
+				unknown(testIP, ErrInvalidCredentials),
+				unknown(testIP, ErrLoginRateLimited),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			setSecurity(t, func(security *config.SecurityConfig) {
+				security.LoginMaxFailures = 3
+				security.LoginFailureWindowMinute = 10
+				security.LoginLockoutMinute = 15
+				security.LoginIPMaxAttempts = 4
+				security.LoginIPWindowMinute = 1
+			})
+			clock := newFakeClock()
+			createTestUser(t, clock, "alice", testPassword)
+			service := &LoginService{Clock: clock}
+
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+				user, err := service.Authenticate(LoginAttempt{Username: step.username, Password: step.password, IP: step.ip})
+				if !errors.Is(err, step.want) {
+					t.Fatalf("第 %d 次登录: 错误为 %v，期望 %v", i+1, err, step.want)
+				}
+				if step.want == nil && (user == nil || user.Username != step.username) {
+					t.Fatalf("第 %d 次登录: 未返回登录用户", i+1)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, clock)
+			}
+		})
+	}
+}
+
+func TestDummyPasswordHashCost(t *testing.T) {
+	// 用户不存在时的比较耗时需与真实密码一致
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("解析固定哈希失败: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Fatalf("固定哈希的成本为 %d，应与创建密码时的 %d 一致", cost, bcrypt.DefaultCost)
+	}
+}
+
+func TestLoginServicePasswordExpired(t *testing.T) {
+	setupTestDB(t)
+	setSecurity(t, func(security *config.SecurityConfig) {
+		security.Password.MaxAgeDay = 30
+	})
+	clock := newFakeClock()
+	createTestUser(t, clock, "alice", testPassword)
+	service := &LoginService{Clock: clock}
+
+	clock.Advance(30 * 24 * time.Hour)
+	if _, err := service.Authenticate(LoginAttempt{Username: "alice", Password: testPassword, IP: testIP}); err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if reloadUser(t, "alice").MustChangePassword {
+		t.Fatal("密码未超过最长使用期限，不应要求修改密码")
+	}
+
+	clock.Advance(time.Second)
+	if _, err := service.Authenticate(LoginAttempt{Username: "alice", Password: testPassword, IP: testIP}); err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if !reloadUser(t, "alice").MustChangePassword {
+		t.Fatal("密码超过最长使用期限后应要求修改密码")
+	}
+}
+
+func TestLoginServiceRecordsReasons(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	createTestUser(t, clock, "alice", testPassword)
+	service := &LoginService{Clock: clock}
+
+	service.Authenticate(LoginAttempt{Username: "nobody", Password: testPassword, IP: testIP})
+	service.Authenticate(LoginAttempt{Username: "alice", Password: "Wrong#Pass000", IP: testIP})
+	service.Authenticate(LoginAttempt{Username: "alice", Password: testPassword, IP: testIP})
+
+	var logs []models.LoginLog
+	if err := models.DB.Order("id").Find(&logs).Error; err != nil {
+		t.Fatalf("查询登录历史失败: %v", err)
+	}
+	want := []string{models.LoginReasonUserNotFound, models.LoginReasonBadPassword, models.LoginReasonSuccess}
+	if len(logs) != len(want) {
+		t.Fatalf("登录历史条数为 %d，期望 %d", len(logs), len(want))
+	}
+	for i, log := range logs {
+		if log.Reason != want[i] || log.Success != (want[i] == models.LoginReasonSuccess) {
+			t.Errorf("第 %d 条登录历史: reason=%s success=%v，期望 reason=%s", i+1, log.Reason, log.Success, want[i])
+		}
+		if !log.CreatedAt.Equal(clock.Now()) {
+			t.Errorf("第 %d 条登录历史时间为 %v，应使用注入的时钟 %v", i+1, log.CreatedAt, clock.Now())
+		}
+	}
+}
+
+// reloadUser 从数据库重新读取用户
+func reloadUser(t *testing.T, username string) *models.User {
+	t.Helper()
+
+	user, err := (&UserService{}).GetUserByUsername(username)
+	if err != nil {
+		t.Fatalf("查询用户 %s 失败: %v", username, err)
+	}
+	return user
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaPeriod TOTP 时间步长（秒）
+	mfaPeriod = 30
+	// mfaSkew 允许前后偏移的时间步数，容忍客户端时钟误差
+	mfaSkew = 1
+	// mfaRecoveryCodeCount 每次生成的恢复码数量
+	mfaRecoveryCodeCount = 10
+	// mfaChallengeMaxAttempts 登录挑战允许的验证码错误次数
+	mfaChallengeMaxAttempts = 5
+)
+
+var (
+	// ErrMFACodeInvalid 验证码或恢复码错误
+	ErrMFACodeInvalid = invalid("code", "mfa_code", "mfa_code_invalid")
+	// ErrMFAChallengeInvalid 登录挑战令牌无效或已过期
+	ErrMFAChallengeInvalid = unauthorized("mfa_challenge_invalid")
+	// ErrMFAAlreadyEnabled 已启用两步验证
+	ErrMFAAlreadyEnabled = conflict("mfa_already_enabled")
+	// ErrMFANotEnabled 未启用两步验证
+	ErrMFANotEnabled = conflict("mfa_not_enabled")
+	// ErrMFANotSetup 启用前未获取密钥
+	ErrMFANotSetup = conflict("mfa_not_setup")
+	// ErrMFARequired 所属角色要求启用两步验证
+	ErrMFARequired = forbidden("mfa_required")
+)
+
+// MFAService 两步验证服务（TOTP，RFC 6238）
+type MFAService struct {
+	Clock utils.Clock // 为空时使用系统时钟
+}
+
+// MFASetup 两步验证绑定信息
+type MFASetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`    // otpauth:// 配置 URI
+	QRCode string `json:"qrCode"` // 配置 URI 的二维码（PNG data URI）
+}
+
+func (s *MFAService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock.Now()
+	}
+	return time.Now()
+}
+
+// Setup 为用户生成待确认的 TOTP 密钥，确认验证码后才会启用
+func (s *MFAService) Setup(userID uint) (*MFASetup, error) {
+	user, err := (&UserService{}).GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      config.GetSecurity().MFAIssuer,
+		AccountName: user.Username,
+		Period:      mfaPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(200, 200)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	if err := models.DB.Model(user).Updates(map[string]interface{}{
+		"mfa_secret":       key.Secret(),
+		"mfa_last_counter": 0,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &MFASetup{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// Enable 校验验证码后启用两步验证，并返回一次性恢复码（仅此一次以明文返回）
+func (s *MFAService) Enable(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if user.MFAEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		if user.MFASecret == "" {
+			return ErrMFANotSetup
+		}
+		if err := s.verifyTOTP(tx, user, code); err != nil {
+			return err
+		}
+
+		if err := tx.Model(user).Update("mfa_enabled", true).Error; err != nil {
+			return err
+		}
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 校验密码及验证码后关闭两步验证；所属角色要求两步验证时不允许关闭
+func (s *MFAService) Disable(userID uint, password, code string) error {
+	required, err := s.Required(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !user.MFAEnabled {
+			return ErrMFANotEnabled
+		}
+		if !(&UserService{}).VerifyPassword(user, password) {
+			return invalid("password", "mismatch", "mfa_password_mismatch")
+		}
+		if err := s.verifyCode(tx, user, code); err != nil {
+			return err
+		}
+		return s.clear(tx, user.ID)
+	})
+}
+
+// Reset 管理员为丢失设备的用户重置两步验证，用户需重新绑定
+func (s *MFAService) Reset(userID uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := findUser(tx, userID); err != nil {
+			return err
+		}
+		return s.clear(tx, userID)
+	})
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !user.MFAEnabled {
+			return ErrMFANotEnabled
+		}
+		if err := s.verifyCode(tx, user, code); err != nil {
+			return err
+		}
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Required 判断用户所属的有效角色是否要求启用两步验证
+func (s *MFAService) Required(userID uint) (bool, error) {
+	var count int64
+	err := models.DB.Model(&models.Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ? AND roles.status = ? AND roles.require_mfa = ?", userID, 1, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateChallenge 密码验证通过后为已启用两步验证的用户签发短期挑战令牌
+func (s *MFAService) CreateChallenge(userID uint) (string, int64, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", 0, err
+	}
+
+	expire := time.Duration(config.GetSecurity().MFAChallengeExpireMinute) * time.Minute
+	now := s.now()
+	if err := models.DB.Create(&models.MFAChallenge{
+		UserID:    userID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(expire),
+		CreatedAt: now,
+	}).Error; err != nil {
+		return "", 0, err
+	}
+	return token, int64(expire.Seconds()), nil
+}
+
+// VerifyChallenge 校验挑战令牌及验证码（TOTP 或恢复码），成功后令牌失效。
+// 验证码错误时返回用户及 ErrMFACodeInvalid；错误次数达到上限或令牌过期时返回 ErrMFAChallengeInvalid。
+func (s *MFAService) VerifyChallenge(token, code string) (*models.User, error) {
+	var user *models.User
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		var challenge models.MFAChallenge
+		if err := tx.Where("token_hash = ?", utils.HashToken(token)).First(&challenge).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMFAChallengeInvalid
+			}
+			return err
+		}
+		if !s.now().Before(challenge.ExpiresAt) || challenge.Attempts >= mfaChallengeMaxAttempts {
+			return ErrMFAChallengeInvalid
+		}
+
+		var err error
+		if user, err = findUser(tx, challenge.UserID); err != nil {
+			return ErrMFAChallengeInvalid
+		}
+
+		// 验证码错误时事务回滚，错误次数在事务外累加
+		if err := s.verifyCode(tx, user, code); err != nil {
+			return err
+		}
+		return tx.Delete(&challenge).Error
+	})
+
+	if errors.Is(err, ErrMFACodeInvalid) {
+		if err := models.DB.Model(&models.MFAChallenge{}).Where("token_hash = ?", utils.HashToken(token)).
+			UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+			return nil, err
+		}
+		return user, ErrMFACodeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// verifyCode 校验 TOTP 验证码，非 6 位数字时按恢复码校验
+func (s *MFAService) verifyCode(tx *gorm.DB, user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		return s.verifyTOTP(tx, user, code)
+	}
+	return s.useRecoveryCode(tx, user.ID, code)
+}
+
+// verifyTOTP 校验 TOTP 验证码，每个时间步的验证码只能使用一次
+func (s *MFAService) verifyTOTP(tx *gorm.DB, user *models.User, code string) error {
+	opts := totp.ValidateOpts{Period: mfaPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	current := s.now().Unix() / mfaPeriod
+
+	for counter := current - mfaSkew; counter <= current+mfaSkew; counter++ {
+		if counter <= user.MFALastCounter {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(user.MFASecret, time.Unix(counter*mfaPeriod, 0), opts)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			user.MFALastCounter = counter
+			return tx.Model(user).Update("mfa_last_counter", counter).Error
+		}
+	}
+	return ErrMFACodeInvalid
+}
+
+// useRecoveryCode 使用恢复码，每个恢复码只能使用一次
+func (s *MFAService) useRecoveryCode(tx *gorm.DB, userID uint, code string) error {
+	result := tx.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", s.now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFACodeInvalid
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 生成新的恢复码并替换旧的恢复码
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		raw, err := utils.RandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		if err := tx.Create(&models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// clear 关闭两步验证并删除恢复码和未完成的登录挑战
+func (s *MFAService) clear(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"mfa_enabled":      false,
+		"mfa_secret":       "",
+		"mfa_last_counter": 0,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.MFAChallenge{}).Error
+}
+
+// normalizeRecoveryCode 统一恢复码格式：去除分隔符和空白并转为小写
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"react-go-admin-backend/models"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpCode 生成指定时间的 TOTP 验证码
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+		Period:    mfaPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		t.Fatalf("生成验证码失败: %v", err)
+	}
+	return code
+}
+
+// enableMFA 为用户绑定并启用两步验证，返回密钥和恢复码
+func enableMFA(t *testing.T, service *MFAService, clock *fakeClock, userID uint) (string, []string) {
+	t.Helper()
+
+	setup, err := service.Setup(userID)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	codes, err := service.Enable(userID, totpCode(t, setup.Secret, clock.Now()))
+	if err != nil {
+		t.Fatalf("启用两步验证失败: %v", err)
+	}
+	if len(codes) != mfaRecoveryCodeCount {
+		t.Fatalf("恢复码数量为 %d，期望 %d", len(codes), mfaRecoveryCodeCount)
+	}
+	return setup.Secret, codes
+}
+
+func TestMFAServiceVerifyTOTP(t *testing.T) {
+	step := mfaPeriod * time.Second
+
+	tests := []struct {
+		name string
+		// advance 启用后推进的时间，offset 生成验证码的时间相对推进后时间的偏移
+		advance time.Duration
+		offset  time.Duration
+		want    error
+	}{
+		{name: "同一时间步的验证码不能重复使用", want: ErrMFACodeInvalid},
+		{name: "下一时间步的验证码", advance: step},
+		{name: "容忍客户端时钟慢一个时间步", advance: 2 * step, offset: -step},
+		{name: "容忍客户端时钟快一个时间步", offset: step},
+		{name: "已使用时间步之前的验证码", advance: step, offset: -step, want: ErrMFACodeInvalid},
+		{name: "超出容忍范围的旧验证码", advance: 3 * step, offset: -2 * step, want: ErrMFACodeInvalid},
+		{name: "超出容忍范围的新验证码", offset: 2 * step, want: ErrMFACodeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			user := createTestUser(t, clock, "alice", testPassword)
+			service := &MFAService{Clock: clock}
+			secret, _ := enableMFA(t, service, clock, user.ID)
+
+			clock.Advance(tt.advance)
+			_, err := service.RegenerateRecoveryCodes(user.ID, totpCode(t, secret, clock.Now().Add(tt.offset)))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("校验错误为 %v，期望 %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMFAServiceReplay(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	user := createTestUser(t, clock, "alice", testPassword)
+	service := &MFAService{Clock: clock}
+	secret, _ := enableMFA(t, service, clock, user.ID)
+
+	clock.Advance(mfaPeriod * time.Second)
+	code := totpCode(t, secret, clock.Now())
+	if _, err := service.RegenerateRecoveryCodes(user.ID, code); err != nil {
+		t.Fatalf("首次使用验证码失败: %v", err)
+	}
+	// 同一验证码在容忍范围内仍有效，但已使用过的时间步不再接受
+	clock.Advance(10 * time.Second)
+	if _, err := service.RegenerateRecoveryCodes(user.ID, code); !errors.Is(err, ErrMFACodeInvalid) {
+		t.Fatalf("重放验证码的错误为 %v，期望 %v", err, ErrMFACodeInvalid)
+	}
+}
+
+func TestMFAServiceRecoveryCode(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	user := createTestUser(t, clock, "alice", testPassword)
+	service := &MFAService{Clock: clock}
+	_, codes := enableMFA(t, service, clock, user.ID)
+
+	tests := []struct {
+		name string
+		code string
+		want error
+	}{
+		{name: "恢复码", code: codes[0]},
+		{name: "恢复码只能使用一次", code: codes[0], want: ErrMFACodeInvalid},
+		{name: "恢复码忽略大小写和分隔符", code: "  " + strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")) + " "},
+		{name: "错误的恢复码", code: "aaaaa-bbbbb", want: ErrMFACodeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := service.CreateChallenge(user.ID)
+			if err != nil {
+				t.Fatalf("创建登录挑战失败: %v", err)
+			}
+			if _, err := service.VerifyChallenge(token, tt.code); !errors.Is(err, tt.want) {
+				t.Fatalf("校验错误为 %v，期望 %v", err, tt.want)
+			}
+		})
+	}
+
+	var used models.MFARecoveryCode
+	if err := models.DB.Where("user_id = ? AND used_at IS NOT NULL", user.ID).First(&used).Error; err != nil {
+		t.Fatalf("查询已使用的恢复码失败: %v", err)
+	}
+	if !used.UsedAt.Equal(clock.Now()) {
+		t.Errorf("恢复码使用时间为 %v，应使用注入的时钟 %v", used.UsedAt, clock.Now())
+	}
+}
+
+func TestMFAServiceChallenge(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wrong   int // 提交正确验证码前的错误次数
+		want    error
+	}{
+		{name: "有效期内验证", advance: 5*time.Minute - time.Second},
+		{name: "挑战过期", advance: 5 * time.Minute, want: ErrMFAChallengeInvalid},
+		{name: "错误次数未达上限", wrong: mfaChallengeMaxAttempts - 1},
+		{name: "错误次数达到上限", wrong: mfaChallengeMaxAttempts, want: ErrMFAChallengeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			user := createTestUser(t, clock, "alice", testPassword)
+			service := &MFAService{Clock: clock}
+			secret, _ := enableMFA(t, service, clock, user.ID)
+			clock.Advance(mfaPeriod * time.Second)
+
+			token, _, err := service.CreateChallenge(user.ID)
+			if err != nil {
+				t.Fatalf("创建登录挑战失败: %v", err)
+			}
+			for i := 0; i < tt.wrong; i++ {
+				if _, err := service.VerifyChallenge(token, "000000"); !errors.Is(err, ErrMFACodeInvalid) {
+					t.Fatalf("第 %d 次错误验证码的错误为 %v，期望 %v", i+1, err, ErrMFACodeInvalid)
+				}
+			}
+
+			clock.Advance(tt.advance)
+			verified, err := service.VerifyChallenge(token, totpCode(t, secret, clock.Now()))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("校验错误为 %v，期望 %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if verified.ID != user.ID {
+				t.Fatalf("返回的用户为 %d，期望 %d", verified.ID, user.ID)
+			}
+			// 验证通过后挑战令牌失效
+			if _, err := service.VerifyChallenge(token, totpCode(t, secret, clock.Now())); !errors.Is(err, ErrMFAChallengeInvalid) {
+				t.Fatalf("再次使用挑战令牌的错误为 %v，期望 %v", err, ErrMFAChallengeInvalid)
+			}
+		})
+	}
+}
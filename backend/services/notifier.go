@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"react-go-admin-backend/config"
+)
+
+// Notification 通知内容
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier 通知发送器，可替换为邮件、短信等实现
+type Notifier interface {
+	Send(notification Notification) error
+}
+
+// LogNotifier 将通知写入日志，用于开发环境
+type LogNotifier struct{}
+
+// Send 写入日志
+func (LogNotifier) Send(notification Notification) error {
+	log.Printf("通知 [%s] %s: %s", notification.To, notification.Subject, notification.Body)
+	return nil
+}
+
+// FileNotifier 将通知以 JSON 行追加写入文件，便于本地测试时读取
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send 追加写入文件
+func (n *FileNotifier) Send(notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(struct {
+		Time time.Time `json:"time"`
+		Notification
+	}{time.Now(), notification})
+}
+
+// NewNotifier 根据配置创建通知发送器
+func NewNotifier(cfg config.NotifyConfig) Notifier {
+	if cfg.Driver == config.NotifyDriverFile {
+		return &FileNotifier{Path: cfg.File}
+	}
+	return LogNotifier{}
+}
+
+var defaultNotifier Notifier = LogNotifier{}
+
+// SetNotifier 设置默认通知发送器
+func SetNotifier(notifier Notifier) {
+	defaultNotifier = notifier
+}
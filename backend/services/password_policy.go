@@ -0,0 +1,204 @@
+package services
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords 常见弱密码集合（小写）
+var commonPasswords = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = true
+		}
+	}
+	return set
+}()
+
+// checkPasswordRules 按密码策略校验长度、字符类型、常见弱密码及是否包含用户名，返回违反的规则
+func checkPasswordRules(field, password, username string) []utils.FieldError {
+	policy := config.GetSecurity().Password
+	var errs []utils.FieldError
+	violate := func(rule string, args ...interface{}) {
+		errs = append(errs, fieldError(field, rule, "password_"+rule, args...))
+	}
+
+	if len([]rune(password)) < policy.MinLength {
+		violate("min_length", policy.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSpecial = true
+		}
+	}
+	if policy.RequireUppercase && !hasUpper {
+		violate("uppercase")
+	}
+	if policy.RequireLowercase && !hasLower {
+		violate("lowercase")
+	}
+	if policy.RequireDigit && !hasDigit {
+		violate("digit")
+	}
+	if policy.RequireSpecial && !hasSpecial {
+		violate("special")
+	}
+
+	if commonPasswords[strings.ToLower(password)] {
+		violate("common")
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violate("username")
+	}
+	return errs
+}
+
+// validatePassword 校验新密码是否符合密码策略；user 不为空时同时检查是否与当前及最近使用过的密码重复
+func validatePassword(tx *gorm.DB, field, password string, user *models.User, username string) error {
+	errs := checkPasswordRules(field, password, username)
+
+	if user != nil && len(errs) == 0 {
+		reused, err := passwordReused(tx, user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			errs = append(errs, fieldError(field, "history", "password_history", config.GetSecurity().Password.HistoryCount))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// passwordReused 判断密码是否与当前密码或最近 N 个历史密码相同
+func passwordReused(tx *gorm.DB, user *models.User, password string) (bool, error) {
+	count := config.GetSecurity().Password.HistoryCount
+	if count == 0 {
+		return false, nil
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+		return true, nil
+	}
+
+	var hashes []string
+	if err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).
+		Order("id DESC").Limit(count).Pluck("password_hash", &hashes).Error; err != nil {
+		return false, err
+	}
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPasswordHistory 记录新密码哈希，仅保留最近 N 条
+func recordPasswordHistory(tx *gorm.DB, userID uint, hash string) error {
+	count := config.GetSecurity().Password.HistoryCount
+	if count == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	var keep []uint
+	if err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Limit(count).Pluck("id", &keep).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
+
+// passwordExpired 判断密码是否超过最长使用期限
+func passwordExpired(user *models.User, now time.Time) bool {
+	maxAge := config.GetSecurity().Password.MaxAgeDay
+	if maxAge == 0 || user.PasswordChangedAt == nil {
+		return false
+	}
+	return now.Sub(*user.PasswordChangedAt) > time.Duration(maxAge)*24*time.Hour
+}
+
+// 临时密码字符集
+const (
+	tempPasswordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	tempPasswordLower   = "abcdefghijkmnpqrstuvwxyz"
+	tempPasswordDigit   = "23456789"
+	tempPasswordSpecial = "!@#$%^&*"
+)
+
+// generateTempPassword 生成符合密码策略的临时密码，每类字符至少包含一个
+func generateTempPassword() (string, error) {
+	length := config.GetSecurity().Password.MinLength
+	if length < 12 {
+		length = 12
+	}
+
+	charsets := []string{tempPasswordUpper, tempPasswordLower, tempPasswordDigit, tempPasswordSpecial}
+	all := strings.Join(charsets, "")
+	password := make([]byte, 0, length)
+	for _, charset := range charsets {
+		c, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// 打乱顺序，避免固定的字符类型位置
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+// randomChar 从字符集中随机选取一个字符
+func randomChar(charset string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+	return charset[n.Int64()], nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/i18n"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrResetTokenInvalid 重置令牌无效、已使用或已过期
+var ErrResetTokenInvalid = invalid("token", "valid", "reset_token_invalid")
+
+// resetRequestInterval 同一用户两次申请重置密码的最小间隔
+const resetRequestInterval = time.Minute
+
+// PasswordResetService 自助重置密码服务
+type PasswordResetService struct {
+	Clock    utils.Clock // 为空时使用系统时钟
+	Notifier Notifier    // 为空时使用默认通知发送器
+}
+
+func (s *PasswordResetService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock.Now()
+	}
+	return time.Now()
+}
+
+func (s *PasswordResetService) notifier() Notifier {
+	if s.Notifier != nil {
+		return s.Notifier
+	}
+	return defaultNotifier
+}
+
+// RequestReset 按用户名或邮箱申请重置密码，生成一次性令牌并通过通知发送器发送。
+// 为避免暴露账号是否存在，账号不存在、已禁用或申请过于频繁时同样返回成功。
+func (s *PasswordResetService) RequestReset(account string) error {
+	var user models.User
+	err := models.DB.Where("status = ?", 1).
+		Where(models.DB.Where("username = ?", account).Or("email = ?", account)).
+		Order("id").First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		log.Printf("用户 %s 未设置邮箱，无法发送重置密码通知", user.Username)
+		return nil
+	}
+
+	now := s.now()
+	var recent int64
+	if err := models.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL AND created_at > ?", user.ID, now.Add(-resetRequestInterval)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(time.Duration(config.GetSecurity().ResetTokenExpireMinute) * time.Minute)
+
+	// 新令牌生成后，之前未使用的令牌全部失效
+	if err := models.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: expiresAt,
+			CreatedAt: now,
+		}).Error
+	}); err != nil {
+		return err
+	}
+
+	// 按收件人的语言偏好发送，未设置时使用默认语言
+	locale := i18n.Normalize(user.Locale)
+	if locale == "" {
+		locale = i18n.DefaultLocale
+	}
+	link := strings.ReplaceAll(config.GetNotify().ResetURL, "{token}", token)
+	if err := s.notifier().Send(Notification{
+		To:      user.Email,
+		Subject: i18n.T(locale, "reset_password_subject"),
+		Body:    i18n.T(locale, "reset_password_body", user.Username, expiresAt.Format("2006-01-02 15:04"), link),
+	}); err != nil {
+		log.Printf("发送重置密码通知失败: %v", err)
+	}
+	return nil
+}
+
+// TokenUser 返回有效的重置令牌所属的用户，令牌无效、已使用或已过期时返回 ErrResetTokenInvalid
+func (s *PasswordResetService) TokenUser(token string) (*models.User, error) {
+	var resetToken models.PasswordResetToken
+	if err := models.DB.Where("token_hash = ?", utils.HashToken(token)).First(&resetToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, err
+	}
+	if resetToken.UsedAt != nil || !s.now().Before(resetToken.ExpiresAt) {
+		return nil, ErrResetTokenInvalid
+	}
+
+	user, err := findUser(models.DB, resetToken.UserID)
+	if err != nil {
+		return nil, ErrResetTokenInvalid
+	}
+	return user, nil
+}
+
+// ResetPassword 使用重置令牌设置新密码。令牌只能使用一次，成功后清除登录锁定并注销已有会话；
+// 新密码不符合密码策略时令牌不会被消耗。
+func (s *PasswordResetService) ResetPassword(token, newPassword string) error {
+	now := s.now()
+
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var resetToken models.PasswordResetToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(token)).First(&resetToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetTokenInvalid
+			}
+			return err
+		}
+		if resetToken.UsedAt != nil || !now.Before(resetToken.ExpiresAt) {
+			return ErrResetTokenInvalid
+		}
+
+		// 条件更新保证并发请求中只有一个能使用该令牌
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenInvalid
+		}
+
+		user, err := findUser(tx, resetToken.UserID)
+		if err != nil {
+			return ErrResetTokenInvalid
+		}
+		if err := validatePassword(tx, "newPassword", newPassword, user, user.Username); err != nil {
+			return err
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password":             string(hashedPassword),
+			"must_change_password": false,
+			"password_changed_at":  now,
+			"failed_login_count":   0,
+			"last_failed_login_at": nil,
+			"locked_until":         nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(tx, user.ID, string(hashedPassword)); err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID, now)
+	})
+}
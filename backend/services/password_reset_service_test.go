@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+)
+
+// captureNotifier 记录发送的通知
+type captureNotifier struct {
+	sent []Notification
+}
+
+func (n *captureNotifier) Send(notification Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestPasswordResetServiceNotificationLocale(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  string
+		subject string
+		body    string
+	}{
+		{name: "未设置语言时使用默认语言", subject: "重置密码", body: "您好 alice"},
+		{name: "按用户的语言偏好发送", locale: "en-US", subject: "Reset your password", body: "Hello alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			user := createTestUser(t, clock, "alice", testPassword)
+			if err := models.DB.Model(user).Updates(map[string]interface{}{"email": "alice@example.com", "locale": tt.locale}).Error; err != nil {
+				t.Fatalf("更新用户失败: %v", err)
+			}
+			notifier := &captureNotifier{}
+			service := &PasswordResetService{Clock: clock, Notifier: notifier}
+
+			if err := service.RequestReset("alice"); err != nil {
+				t.Fatalf("申请重置密码失败: %v", err)
+			}
+			if len(notifier.sent) != 1 {
+				t.Fatalf("发送了 %d 条通知，期望 1", len(notifier.sent))
+			}
+			sent := notifier.sent[0]
+			if sent.Subject != tt.subject {
+				t.Errorf("通知标题为 %q，期望 %q", sent.Subject, tt.subject)
+			}
+			if !strings.HasPrefix(sent.Body, tt.body) {
+				t.Errorf("通知内容为 %q，期望以 %q 开头", sent.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestUserServiceBatchResetPasswordPolicy(t *testing.T) {
+	setupTestDB(t)
+	setSecurity(t, func(security *config.SecurityConfig) {
+		security.Password.HistoryCount = 3
+	})
+	clock := newFakeClock()
+	service := &UserService{Clock: clock}
+	alice := createTestUser(t, clock, "alice", testPassword)
+	bob := createTestUser(t, clock, "bob", "Bright#Owl456")
+
+	tests := []struct {
+		name     string
+		password string
+		// rules 每个用户期望违反的规则，为空表示重置成功
+		rules map[uint]string
+	}{
+		{name: "密码包含用户名", password: "alice#Pass789", rules: map[uint]string{alice.ID: "username"}},
+		{name: "密码与历史密码重复", password: testPassword, rules: map[uint]string{alice.ID: "history"}},
+		{name: "符合密码策略", password: "Calm#River321"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.BatchResetPassword(0, []uint{alice.ID, bob.ID}, tt.password)
+			if err != nil {
+				t.Fatalf("批量重置密码失败: %v", err)
+			}
+			for _, result := range results {
+				rule := tt.rules[result.ID]
+				if rule == "" {
+					if !result.Success {
+						t.Errorf("用户 %d 重置失败: %v", result.ID, result.Err)
+					}
+					continue
+				}
+				var validationErr *ValidationError
+				if result.Success || !errors.As(result.Err, &validationErr) || validationErr.Fields[0].Rule != rule {
+					t.Errorf("用户 %d 的结果为 %+v，期望违反规则 %s", result.ID, result, rule)
+				}
+			}
+		})
+	}
+
+	// 通用规则在重置前统一校验
+	if _, err := service.BatchResetPassword(0, []uint{alice.ID}, "short"); err == nil {
+		t.Error("不符合密码策略时应返回错误")
+	}
+}
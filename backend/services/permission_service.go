@@ -0,0 +1,327 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermissionService 权限服务
+type PermissionService struct{}
+
+var (
+	// ErrPermissionNotFound 权限不存在
+	ErrPermissionNotFound = notFound("permission_not_found")
+	// ErrPermissionCodeExists 权限代码已存在
+	ErrPermissionCodeExists = conflict("permission_code_exists")
+	// ErrPermissionHasChildren 删除仍有子权限的权限
+	ErrPermissionHasChildren = conflict("permission_has_children")
+)
+
+// PermissionTree 权限树节点
+type PermissionTree struct {
+	models.Permission
+	Children []*PermissionTree `json:"children"`
+}
+
+// permissionSortable 权限列表可排序字段
+var permissionSortable = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"code":       "code",
+	"sort":       "sort",
+	"createdAt":  "created_at",
+	"created_at": "created_at",
+}
+
+// GetPermissionList 获取权限列表，支持关键词、创建时间过滤及排序，默认按 sort 排序
+func (s *PermissionService) GetPermissionList(query ListQuery) ([]models.Permission, int64, error) {
+	var permissions []models.Permission
+	var total int64
+
+	query.Normalize()
+	// 权限没有状态字段
+	query.Status = nil
+	if query.SortBy == "" {
+		query.SortBy = "sort"
+	}
+
+	db := query.applyFilters(models.DB.Model(&models.Permission{}), "permissions", "name", "code", "description")
+
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.applyOrder(db, "permissions", permissionSortable).
+		Offset(query.Offset()).Limit(query.PageSize).Find(&permissions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return permissions, total, nil
+}
+
+// GetAllPermissions 获取全部权限
+func (s *PermissionService) GetAllPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := models.DB.Order("sort, id").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetPermissionTree 获取权限树
+func (s *PermissionService) GetPermissionTree() ([]*PermissionTree, error) {
+	permissions, err := s.GetAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+	return BuildPermissionTree(permissions), nil
+}
+
+// GetUserPermissions 获取用户所有有效角色（未删除且启用）拥有的权限
+func (s *PermissionService) GetUserPermissions(userID uint) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := models.DB.Model(&models.Permission{}).
+		Where("permissions.id IN (?)", models.DB.Table("role_permissions").
+			Select("role_permissions.permission_id").
+			Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("user_roles.user_id = ? AND roles.deleted_at IS NULL AND roles.status = ?", userID, 1)).
+		Order("sort, id").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetUserMenuTree 获取用户可访问的菜单树（Type=1）
+func (s *PermissionService) GetUserMenuTree(permissions []models.Permission) []*PermissionTree {
+	menus := make([]models.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if permission.Type == 1 {
+			menus = append(menus, permission)
+		}
+	}
+	return BuildPermissionTree(menus)
+}
+
+// BuildPermissionTree 按 ParentCode 构建权限树，同级节点按 Sort 排序
+func BuildPermissionTree(permissions []models.Permission) []*PermissionTree {
+	nodes := make(map[string]*PermissionTree, len(permissions))
+	for _, permission := range permissions {
+		nodes[permission.Code] = &PermissionTree{Permission: permission, Children: []*PermissionTree{}}
+	}
+
+	roots := []*PermissionTree{}
+	for _, permission := range permissions {
+		node := nodes[permission.Code]
+		if parent, ok := nodes[permission.ParentCode]; ok && permission.ParentCode != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortPermissionTree(roots)
+	return roots
+}
+
+// sortPermissionTree 递归排序权限树
+func sortPermissionTree(nodes []*PermissionTree) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Sort != nodes[j].Sort {
+			return nodes[i].Sort < nodes[j].Sort
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	for _, node := range nodes {
+		sortPermissionTree(node.Children)
+	}
+}
+
+// GetPermissionByID 根据 ID 获取权限
+func (s *PermissionService) GetPermissionByID(id uint) (*models.Permission, error) {
+	var permission models.Permission
+	if err := models.DB.First(&permission, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPermissionNotFound
+		}
+		return nil, err
+	}
+	return &permission, nil
+}
+
+// GetPermissionByCode 根据代码获取权限
+func (s *PermissionService) GetPermissionByCode(code string) (*models.Permission, error) {
+	var permission models.Permission
+	if err := models.DB.Where("code = ?", code).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPermissionNotFound
+		}
+		return nil, err
+	}
+	return &permission, nil
+}
+
+// CreatePermission 创建权限
+func (s *PermissionService) CreatePermission(permission *models.Permission) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPermissionCode(tx, permission.Code); err != nil {
+			return err
+		}
+		if err := validateParent(tx, permission.Code, permission.ParentCode); err != nil {
+			return err
+		}
+		return tx.Create(permission).Error
+	})
+}
+
+// UpdatePermission 更新权限，代码唯一性、父权限及环的校验与写入在同一事务内完成
+func (s *PermissionService) UpdatePermission(id uint, updates map[string]interface{}) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var permission models.Permission
+		if err := tx.First(&permission, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPermissionNotFound
+			}
+			return err
+		}
+
+		code := permission.Code
+		if newCode, ok := updates["code"].(string); ok && newCode != permission.Code {
+			if err := checkPermissionCode(tx, newCode); err != nil {
+				return err
+			}
+			code = newCode
+		}
+
+		parentCode := permission.ParentCode
+		if newParent, ok := updates["parent_code"].(string); ok {
+			parentCode = newParent
+		}
+
+		// 父权限不能是自身或自身的子孙节点
+		if parentCode != "" {
+			if parentCode == code {
+				return invalid("parent_code", "ne", "parent_permission_self")
+			}
+			if err := validateParent(tx, permission.Code, parentCode); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.Permission{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// 代码变更时同步子权限的 ParentCode
+		if code != permission.Code {
+			return tx.Model(&models.Permission{}).Where("parent_code = ?", permission.Code).
+				Update("parent_code", code).Error
+		}
+		return nil
+	})
+}
+
+// DeletePermission 删除权限，仍分配给角色时返回 ConflictError，force 为 true 时一并清理角色授权
+func (s *PermissionService) DeletePermission(id uint, force bool) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var permission models.Permission
+		if err := tx.First(&permission, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPermissionNotFound
+			}
+			return err
+		}
+
+		// 存在子权限时不允许删除，避免产生孤儿节点
+		var count int64
+		if err := tx.Model(&models.Permission{}).Where("parent_code = ?", permission.Code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPermissionHasChildren
+		}
+
+		// 仍分配给角色时需显式强制删除
+		if !force {
+			var roles []PermissionRoleRef
+			if err := tx.Unscoped().Model(&models.Role{}).
+				Select("roles.id, roles.name, roles.code").
+				Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+				Where("role_permissions.permission_id = ?", permission.ID).
+				Order("roles.id").
+				Scan(&roles).Error; err != nil {
+				return err
+			}
+			if len(roles) > 0 {
+				err := conflict("permission_in_use", len(roles))
+				err.Details = map[string]interface{}{"roles": roles}
+				return err
+			}
+		}
+
+		return guardRoleManagers(tx, func() error {
+			if err := tx.Model(&permission).Association("Roles").Clear(); err != nil {
+				return err
+			}
+			return tx.Delete(&permission).Error
+		})
+	})
+}
+
+// checkPermissionCode 检查权限代码是否已被使用
+func checkPermissionCode(tx *gorm.DB, code string) error {
+	var count int64
+	if err := tx.Model(&models.Permission{}).Where("code = ?", code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPermissionCodeExists
+	}
+	return nil
+}
+
+// validateParent 校验父权限存在且不会形成环。
+// 读取权限树时加行锁，使并发修改父权限的事务依次执行，避免各自校验通过后共同形成环（SQLite 本身串行写入，忽略行锁）
+func validateParent(tx *gorm.DB, code, parentCode string) error {
+	if parentCode == "" {
+		return nil
+	}
+	if parentCode == code {
+		return invalid("parent_code", "ne", "parent_permission_self")
+	}
+
+	var permissions []models.Permission
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("code", "parent_code").Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	parents := make(map[string]string, len(permissions))
+	for _, permission := range permissions {
+		parents[permission.Code] = permission.ParentCode
+	}
+
+	if _, ok := parents[parentCode]; !ok {
+		return invalid("parent_code", "exists", "parent_permission_not_found")
+	}
+
+	// 沿父链向上查找，若回到自身则说明存在环
+	visited := map[string]bool{}
+	for current := parentCode; current != ""; current = parents[current] {
+		if current == code {
+			return invalid("parent_code", "cycle", "parent_permission_descendant")
+		}
+		if visited[current] {
+			return invalid("parent_code", "cycle", "permission_tree_cycle")
+		}
+		visited[current] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"react-go-admin-backend/models"
+)
+
+// assertRule 校验错误为违反指定规则的 ValidationError，rule 为空时期望没有错误
+func assertRule(t *testing.T, err error, rule string) {
+	t.Helper()
+
+	if rule == "" {
+		if err != nil {
+			t.Fatalf("期望成功，实际错误为 %v", err)
+		}
+		return
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields[0].Rule != rule {
+		t.Fatalf("错误为 %v，期望违反规则 %s", err, rule)
+	}
+}
+
+func TestPermissionServiceParent(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(service *PermissionService, tree map[string]*models.Permission) error
+		rule   string
+	}{
+		{
+			name: "创建时父权限不存在",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.CreatePermission(&models.Permission{Name: "D", Code: "d", ParentCode: "missing"})
+			},
+			rule: "exists",
+		},
+		{
+			name: "创建时父权限为自身",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.CreatePermission(&models.Permission{Name: "D", Code: "d", ParentCode: "d"})
+			},
+			rule: "ne",
+		},
+		{
+			name: "修改父权限为自身",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.UpdatePermission(tree["a"].ID, map[string]interface{}{"parent_code": "a"})
+			},
+			rule: "ne",
+		},
+		{
+			name: "修改父权限为子孙节点",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.UpdatePermission(tree["a"].ID, map[string]interface{}{"parent_code": "c"})
+			},
+			rule: "cycle",
+		},
+		{
+			name: "修改代码后父权限为自身的子节点",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.UpdatePermission(tree["b"].ID, map[string]interface{}{"code": "b2", "parent_code": "c"})
+			},
+			rule: "cycle",
+		},
+		{
+			name: "修改时父权限不存在",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.UpdatePermission(tree["c"].ID, map[string]interface{}{"parent_code": "missing"})
+			},
+			rule: "exists",
+		},
+		{
+			name: "移动到其他分支",
+			change: func(service *PermissionService, tree map[string]*models.Permission) error {
+				return service.UpdatePermission(tree["c"].ID, map[string]interface{}{"parent_code": "a"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			service := &PermissionService{}
+			// a -> b -> c
+			tree := map[string]*models.Permission{
+				"a": {Name: "A", Code: "a"},
+				"b": {Name: "B", Code: "b", ParentCode: "a"},
+				"c": {Name: "C", Code: "c", ParentCode: "b"},
+			}
+			for _, code := range []string{"a", "b", "c"} {
+				if err := service.CreatePermission(tree[code]); err != nil {
+					t.Fatalf("创建权限 %s 失败: %v", code, err)
+				}
+			}
+
+			assertRule(t, tt.change(service, tree), tt.rule)
+		})
+	}
+}
+
+func TestPermissionServiceUpdateCode(t *testing.T) {
+	setupTestDB(t)
+	service := &PermissionService{}
+	parent := models.Permission{Name: "A", Code: "a"}
+	child := models.Permission{Name: "B", Code: "b", ParentCode: "a"}
+	for _, permission := range []*models.Permission{&parent, &child} {
+		if err := service.CreatePermission(permission); err != nil {
+			t.Fatalf("创建权限失败: %v", err)
+		}
+	}
+
+	if err := service.CreatePermission(&models.Permission{Name: "A", Code: "a"}); !errors.Is(err, ErrPermissionCodeExists) {
+		t.Fatalf("重复代码的错误为 %v，期望 %v", err, ErrPermissionCodeExists)
+	}
+	if err := service.UpdatePermission(child.ID, map[string]interface{}{"code": "a"}); !errors.Is(err, ErrPermissionCodeExists) {
+		t.Fatalf("修改为已有代码的错误为 %v，期望 %v", err, ErrPermissionCodeExists)
+	}
+
+	// 修改代码后子权限的 ParentCode 同步更新
+	if err := service.UpdatePermission(parent.ID, map[string]interface{}{"code": "a2"}); err != nil {
+		t.Fatalf("修改权限代码失败: %v", err)
+	}
+	updated, err := service.GetPermissionByID(child.ID)
+	if err != nil {
+		t.Fatalf("查询权限失败: %v", err)
+	}
+	if updated.ParentCode != "a2" {
+		t.Errorf("子权限的父代码为 %s，期望 a2", updated.ParentCode)
+	}
+}
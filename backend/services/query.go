@@ -0,0 +1,80 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListQuery 通用列表查询参数
+type ListQuery struct {
+	Page        int
+	PageSize    int
+	Keyword     string
+	Status      *int
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	SortOrder   string
+}
+
+// Normalize 修正分页参数
+func (q *ListQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = 10
+	}
+	if q.PageSize > 100 {
+		q.PageSize = 100
+	}
+}
+
+// Offset 计算分页偏移量
+func (q *ListQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// likeEscaper 转义 LIKE 通配符，使关键词中的 %、_ 按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// applyFilters 应用关键词、状态和创建时间过滤，keywordColumns 为关键词匹配的列
+func (q *ListQuery) applyFilters(db *gorm.DB, table string, keywordColumns ...string) *gorm.DB {
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" && len(keywordColumns) > 0 {
+		conditions := make([]string, 0, len(keywordColumns))
+		args := make([]interface{}, 0, len(keywordColumns))
+		pattern := "%" + likeEscaper.Replace(keyword) + "%"
+		for _, column := range keywordColumns {
+			// 转义字符以参数传入，避免 MySQL 将字面量中的反斜杠视为转义
+			conditions = append(conditions, table+"."+column+" LIKE ? ESCAPE ?")
+			args = append(args, pattern, `\`)
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	if q.Status != nil {
+		db = db.Where(table+".status = ?", *q.Status)
+	}
+	if q.CreatedFrom != nil {
+		db = db.Where(table+".created_at >= ?", *q.CreatedFrom)
+	}
+	if q.CreatedTo != nil {
+		db = db.Where(table+".created_at < ?", *q.CreatedTo)
+	}
+	return db
+}
+
+// applyOrder 按白名单字段排序，未知字段使用默认排序
+func (q *ListQuery) applyOrder(db *gorm.DB, table string, sortable map[string]string) *gorm.DB {
+	column, ok := sortable[q.SortBy]
+	if !ok {
+		return db.Order(table + ".id ASC")
+	}
+
+	direction := "ASC"
+	if strings.EqualFold(q.SortOrder, "desc") || strings.EqualFold(q.SortOrder, "descend") {
+		direction = "DESC"
+	}
+	return db.Order(table + "." + column + " " + direction).Order(table + ".id ASC")
+}
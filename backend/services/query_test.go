@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"react-go-admin-backend/models"
+)
+
+func TestListQueryKeywordEscape(t *testing.T) {
+	setupTestDB(t)
+	service := &RoleService{}
+	for _, code := range []string{"ops_admin", "opsXadmin", "100%", "1000", `a\b`, "ab"} {
+		if err := models.DB.Create(&models.Role{Name: code, Code: code, Status: 1}).Error; err != nil {
+			t.Fatalf("创建角色 %s 失败: %v", code, err)
+		}
+	}
+
+	tests := []struct {
+		keyword string
+		want    []string
+	}{
+		{keyword: "_", want: []string{"ops_admin"}},
+		{keyword: "%", want: []string{"100%"}},
+		{keyword: `\`, want: []string{`a\b`}},
+		{keyword: "ops", want: []string{"ops_admin", "opsXadmin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword, func(t *testing.T) {
+			roles, total, err := service.GetRoleList(ListQuery{Keyword: tt.keyword, Page: 1, PageSize: 10})
+			if err != nil {
+				t.Fatalf("查询角色失败: %v", err)
+			}
+			got := make([]string, 0, len(roles))
+			for _, role := range roles {
+				got = append(got, role.Code)
+			}
+			if int(total) != len(tt.want) || len(got) != len(tt.want) {
+				t.Fatalf("关键词 %q 匹配到 %v，期望 %v", tt.keyword, got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("关键词 %q 匹配到 %v，期望 %v", tt.keyword, got, tt.want)
+				}
+			}
+		})
+	}
+}
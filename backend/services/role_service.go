@@ -2,24 +2,59 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
 	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
 )
 
 // RoleService 角色服务
 type RoleService struct{}
 
-// GetRoleList 获取角色列表
-func (s *RoleService) GetRoleList(page, pageSize int) ([]models.Role, int64, error) {
+var (
+	// ErrRoleNotFound 角色不存在
+	ErrRoleNotFound = notFound("role_not_found")
+	// ErrDeletedRoleNotFound 回收站中不存在该角色
+	ErrDeletedRoleNotFound = notFound("deleted_role_not_found")
+	// ErrRoleCodeExists 角色代码已存在
+	ErrRoleCodeExists = conflict("role_code_exists")
+	// ErrRoleCodeDeleted 角色代码被回收站中的角色占用
+	ErrRoleCodeDeleted = conflict("role_code_deleted")
+	// ErrSystemRoleCode 修改系统内置角色的代码
+	ErrSystemRoleCode = forbidden("system_role_code")
+	// ErrSystemRoleDelete 删除系统内置角色
+	ErrSystemRoleDelete = forbidden("system_role_delete")
+)
+
+// roleSortable 角色列表可排序字段
+var roleSortable = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"code":       "code",
+	"status":     "status",
+	"createdAt":  "created_at",
+	"created_at": "created_at",
+	"updatedAt":  "updated_at",
+	"updated_at": "updated_at",
+}
+
+// GetRoleList 获取角色列表，支持关键词、状态、创建时间过滤及排序
+func (s *RoleService) GetRoleList(query ListQuery) ([]models.Role, int64, error) {
 	var roles []models.Role
 	var total int64
 
-	offset := (page - 1) * pageSize
+	query.Normalize()
+
+	db := query.applyFilters(models.DB.Model(&models.Role{}), "roles", "name", "code", "description")
 
-	if err := models.DB.Model(&models.Role{}).Count(&total).Error; err != nil {
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := models.DB.Offset(offset).Limit(pageSize).Find(&roles).Error; err != nil {
+	if err := query.applyOrder(db, "roles", roleSortable).
+		Offset(query.Offset()).Limit(query.PageSize).Find(&roles).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -30,6 +65,9 @@ func (s *RoleService) GetRoleList(page, pageSize int) ([]models.Role, int64, err
 func (s *RoleService) GetRoleByID(id uint) (*models.Role, error) {
 	var role models.Role
 	if err := models.DB.Preload("Permissions").First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
 		return nil, err
 	}
 	return &role, nil
@@ -39,6 +77,9 @@ func (s *RoleService) GetRoleByID(id uint) (*models.Role, error) {
 func (s *RoleService) GetRoleByCode(code string) (*models.Role, error) {
 	var role models.Role
 	if err := models.DB.Preload("Permissions").Where("code = ?", code).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
 		return nil, err
 	}
 	return &role, nil
@@ -46,22 +87,270 @@ func (s *RoleService) GetRoleByCode(code string) (*models.Role, error) {
 
 // CreateRole 创建角色
 func (s *RoleService) CreateRole(role *models.Role) error {
-	// 检查角色代码是否已存在
-	var count int64
-	models.DB.Model(&models.Role{}).Where("code = ?", role.Code).Count(&count)
-	if count > 0 {
-		return errors.New("角色代码已存在")
+	if err := checkRoleCode(models.DB, role.Code, 0); err != nil {
+		return err
 	}
 
 	return models.DB.Create(role).Error
 }
 
-// UpdateRole 更新角色
+// checkRoleCode 检查角色代码是否已被其他角色（含回收站中的角色）使用，excludeID 为正在修改的角色
+func checkRoleCode(tx *gorm.DB, code string, excludeID uint) error {
+	var existing models.Role
+	err := tx.Unscoped().Where("code = ? AND id <> ?", code, excludeID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.DeletedAt.Valid {
+		return ErrRoleCodeDeleted
+	}
+	return ErrRoleCodeExists
+}
+
+// UpdateRole 更新角色，系统内置角色不允许修改代码；禁用角色后不能没有可管理角色的有效用户
 func (s *RoleService) UpdateRole(id uint, updates map[string]interface{}) error {
-	return models.DB.Model(&models.Role{}).Where("id = ?", id).Updates(updates).Error
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.First(&role, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+		if code, ok := updates["code"].(string); ok && code != role.Code {
+			if role.IsSystem {
+				return ErrSystemRoleCode
+			}
+			if err := checkRoleCode(tx, code, id); err != nil {
+				return err
+			}
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if _, ok := updates["status"]; ok {
+			return guardRoleManagers(tx, func() error {
+				return tx.Model(&role).Updates(updates).Error
+			})
+		}
+		return tx.Model(&role).Updates(updates).Error
+	})
+}
+
+// DeleteRoleOptions 删除角色选项
+type DeleteRoleOptions struct {
+	Force             bool // 角色仍被用户使用时强制删除
+	ReplacementRoleID uint // 强制删除时，受影响用户改为关联的替代角色
+}
+
+// DeleteRole 删除角色（移入回收站），权限关联保留以便恢复。
+// 角色仍被用户使用时返回 ConflictError 列出受影响的用户；指定 Force 时在同一事务内将这些用户改为关联替代角色并清理其与原角色的关联。
+func (s *RoleService) DeleteRole(id uint, opts DeleteRoleOptions) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.First(&role, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+		if role.IsSystem {
+			return ErrSystemRoleDelete
+		}
+
+		// 包含回收站中的用户，恢复后同样需要有效的角色
+		var users []RoleUserRef
+		if err := tx.Unscoped().Model(&models.User{}).
+			Select("users.id, users.username, users.realname").
+			Joins("JOIN user_roles ON user_roles.user_id = users.id").
+			Where("user_roles.role_id = ?", role.ID).
+			Order("users.id").
+			Scan(&users).Error; err != nil {
+			return err
+		}
+
+		if len(users) > 0 {
+			if !opts.Force {
+				err := conflict("role_in_use", len(users))
+				err.Details = map[string]interface{}{"users": users}
+				return err
+			}
+
+			replacement, err := s.findReplacement(tx, role.ID, opts.ReplacementRoleID)
+			if err != nil {
+				return err
+			}
+			for _, user := range users {
+				if err := tx.Model(&models.User{ID: user.ID}).Association("Roles").Append(replacement); err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", role.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// 保留权限关联，恢复角色时一并恢复；删除期间查询时按 roles.deleted_at 过滤
+		result := tx.Delete(&role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleNotFound
+		}
+		return nil
+	})
+}
+
+// findReplacement 查找强制删除角色时使用的替代角色
+func (s *RoleService) findReplacement(tx *gorm.DB, roleID, replacementID uint) (*models.Role, error) {
+	if replacementID == 0 {
+		return nil, invalid("replacementRoleId", "required", "replacement_role_required")
+	}
+	if replacementID == roleID {
+		return nil, invalid("replacementRoleId", "ne", "replacement_role_self")
+	}
+
+	var replacement models.Role
+	if err := tx.First(&replacement, replacementID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid("replacementRoleId", "exists", "replacement_role_not_found")
+		}
+		return nil, err
+	}
+	return &replacement, nil
 }
 
-// DeleteRole 删除角色
-func (s *RoleService) DeleteRole(id uint) error {
-	return models.DB.Delete(&models.Role{}, id).Error
+// GetDeletedRoleList 获取回收站中的角色
+func (s *RoleService) GetDeletedRoleList(query ListQuery) ([]models.Role, int64, error) {
+	var roles []models.Role
+	var total int64
+
+	query.Normalize()
+
+	db := query.applyFilters(models.DB.Unscoped().Model(&models.Role{}).Where("roles.deleted_at IS NOT NULL"),
+		"roles", "name", "code", "description")
+
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.Order("roles.deleted_at DESC").
+		Offset(query.Offset()).Limit(query.PageSize).Find(&roles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return roles, total, nil
+}
+
+// RestoreRole 从回收站恢复角色，权限关联随角色一并恢复
+func (s *RoleService) RestoreRole(id uint) error {
+	result := models.DB.Unscoped().Model(&models.Role{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeletedRoleNotFound
+	}
+	return nil
+}
+
+// PurgeRole 彻底删除回收站中的角色，并清理用户和权限关联
+func (s *RoleService) PurgeRole(id uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeletedRoleNotFound
+			}
+			return err
+		}
+
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&role).Association("Users").Clear(); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&role).Error
+	})
+}
+
+// AssignPermissions 替换角色的权限集合，支持按 ID 或代码指定，includeParents 为 true 时自动补充父权限；
+// 移除授权后不能没有可管理角色的有效用户
+func (s *RoleService) AssignPermissions(roleID uint, ids []uint, codes []string, includeParents bool) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.First(&role, roleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+
+		var all []models.Permission
+		if err := tx.Find(&all).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]models.Permission, len(all))
+		byCode := make(map[string]models.Permission, len(all))
+		for _, permission := range all {
+			byID[permission.ID] = permission
+			byCode[permission.Code] = permission
+		}
+
+		selected := make(map[uint]models.Permission)
+		var unknown []string
+		for _, id := range ids {
+			permission, ok := byID[id]
+			if !ok {
+				unknown = append(unknown, fmt.Sprint(id))
+				continue
+			}
+			selected[permission.ID] = permission
+		}
+		for _, code := range codes {
+			permission, ok := byCode[code]
+			if !ok {
+				unknown = append(unknown, code)
+				continue
+			}
+			selected[permission.ID] = permission
+		}
+		if len(unknown) > 0 {
+			return invalid("permissions", "exists", "permissions_not_found", strings.Join(unknown, ", "))
+		}
+
+		// 授予子权限时自动补充其所有祖先权限
+		if includeParents {
+			for _, permission := range selected {
+				for parentCode := permission.ParentCode; parentCode != ""; {
+					parent, ok := byCode[parentCode]
+					if !ok {
+						break
+					}
+					if _, exists := selected[parent.ID]; exists {
+						break
+					}
+					selected[parent.ID] = parent
+					parentCode = parent.ParentCode
+				}
+			}
+		}
+
+		permissions := make([]models.Permission, 0, len(selected))
+		for _, permission := range selected {
+			permissions = append(permissions, permission)
+		}
+
+		return guardRoleManagers(tx, func() error {
+			return tx.Model(&role).Association("Permissions").Replace(permissions)
+		})
+	})
 }
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"react-go-admin-backend/models"
+)
+
+func TestRoleServiceSoftDelete(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	roleService := &RoleService{}
+	userService := &UserService{Clock: clock}
+
+	permission := models.Permission{Name: "用户查看", Code: "system:user:view", Type: 2}
+	if err := models.DB.Create(&permission).Error; err != nil {
+		t.Fatalf("创建权限失败: %v", err)
+	}
+	editor := models.Role{Name: "编辑", Code: "editor", Status: 1}
+	viewer := models.Role{Name: "访客", Code: "viewer", Status: 1}
+	for _, role := range []*models.Role{&editor, &viewer} {
+		if err := roleService.CreateRole(role); err != nil {
+			t.Fatalf("创建角色 %s 失败: %v", role.Code, err)
+		}
+	}
+	if err := roleService.AssignPermissions(editor.ID, []uint{permission.ID}, nil, false); err != nil {
+		t.Fatalf("分配权限失败: %v", err)
+	}
+	user := createTestUser(t, clock, "alice", testPassword)
+	if err := userService.AddRoles(user.ID, []uint{editor.ID}); err != nil {
+		t.Fatalf("分配角色失败: %v", err)
+	}
+
+	if err := roleService.DeleteRole(editor.ID, DeleteRoleOptions{Force: true, ReplacementRoleID: viewer.ID}); err != nil {
+		t.Fatalf("删除角色失败: %v", err)
+	}
+	assertUserRoles(t, userService, user.ID, "viewer")
+	assertPermission(t, userService, user.ID, permission.Code, false)
+
+	// 改为关联替代角色的用户在同一事务内移除原角色的关联
+	var count int64
+	if err := models.DB.Table("user_roles").Where("role_id = ?", editor.ID).Count(&count).Error; err != nil {
+		t.Fatalf("查询用户角色失败: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("已删除角色仍有 %d 条用户关联", count)
+	}
+
+	if err := roleService.RestoreRole(editor.ID); err != nil {
+		t.Fatalf("恢复角色失败: %v", err)
+	}
+	assertUserRoles(t, userService, user.ID, "viewer")
+	assertPermission(t, userService, user.ID, permission.Code, false)
+	restored, err := roleService.GetRoleByID(editor.ID)
+	if err != nil {
+		t.Fatalf("查询角色失败: %v", err)
+	}
+	if len(restored.Permissions) != 1 {
+		t.Errorf("恢复后角色的权限数为 %d，期望 1", len(restored.Permissions))
+	}
+
+	if err := roleService.DeleteRole(editor.ID, DeleteRoleOptions{Force: true, ReplacementRoleID: viewer.ID}); err != nil {
+		t.Fatalf("再次删除角色失败: %v", err)
+	}
+	if err := roleService.PurgeRole(editor.ID); err != nil {
+		t.Fatalf("彻底删除角色失败: %v", err)
+	}
+	for _, table := range []string{"user_roles", "role_permissions"} {
+		var count int64
+		if err := models.DB.Table(table).Where("role_id = ?", editor.ID).Count(&count).Error; err != nil {
+			t.Fatalf("查询 %s 失败: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("彻底删除角色后 %s 仍有 %d 条关联", table, count)
+		}
+	}
+}
+
+func TestRoleServiceRoleManagerGuard(t *testing.T) {
+	tests := []struct {
+		name string
+		// secondManager 是否另有用户通过其他角色拥有角色编辑权限
+		secondManager bool
+		change        func(service *RoleService, role *models.Role) error
+		want          error
+	}{
+		{
+			name: "禁用唯一可管理角色的角色",
+			change: func(service *RoleService, role *models.Role) error {
+				return service.UpdateRole(role.ID, map[string]interface{}{"status": 0})
+			},
+			want: ErrLastRoleManager,
+		},
+		{
+			name: "移除唯一可管理角色的授权",
+			change: func(service *RoleService, role *models.Role) error {
+				return service.AssignPermissions(role.ID, nil, nil, false)
+			},
+			want: ErrLastRoleManager,
+		},
+		{
+			name: "强制删除角色编辑权限",
+			change: func(service *RoleService, role *models.Role) error {
+				return (&PermissionService{}).DeletePermission(role.Permissions[0].ID, true)
+			},
+			want: ErrLastRoleManager,
+		},
+		{
+			name: "修改角色名称",
+			change: func(service *RoleService, role *models.Role) error {
+				return service.UpdateRole(role.ID, map[string]interface{}{"name": "管理员"})
+			},
+		},
+		{
+			name:          "仍有其他用户可管理角色",
+			secondManager: true,
+			change: func(service *RoleService, role *models.Role) error {
+				return service.UpdateRole(role.ID, map[string]interface{}{"status": 0})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			roleService := &RoleService{}
+			userService := &UserService{Clock: clock}
+
+			permission := models.Permission{Name: "角色编辑", Code: roleManagerPermission, Type: 2}
+			if err := models.DB.Create(&permission).Error; err != nil {
+				t.Fatalf("创建权限失败: %v", err)
+			}
+			admin := models.Role{Name: "超级管理员", Code: "admin", Status: 1, Permissions: []models.Permission{permission}}
+			if err := models.DB.Create(&admin).Error; err != nil {
+				t.Fatalf("创建角色失败: %v", err)
+			}
+			alice := createTestUser(t, clock, "alice", testPassword)
+			if err := userService.AddRoles(alice.ID, []uint{admin.ID}); err != nil {
+				t.Fatalf("分配角色失败: %v", err)
+			}
+			if tt.secondManager {
+				editor := models.Role{Name: "编辑", Code: "editor", Status: 1, Permissions: []models.Permission{permission}}
+				if err := models.DB.Create(&editor).Error; err != nil {
+					t.Fatalf("创建角色失败: %v", err)
+				}
+				bob := createTestUser(t, clock, "bob", testPassword)
+				if err := userService.AddRoles(bob.ID, []uint{editor.ID}); err != nil {
+					t.Fatalf("分配角色失败: %v", err)
+				}
+			}
+
+			if err := tt.change(roleService, &admin); !errors.Is(err, tt.want) {
+				t.Fatalf("操作错误为 %v，期望 %v", err, tt.want)
+			}
+			// 被拒绝的操作整体回滚
+			if tt.want != nil {
+				assertPermission(t, userService, alice.ID, roleManagerPermission, true)
+			}
+		})
+	}
+}
+
+// assertUserRoles 校验用户的有效角色代码
+func assertUserRoles(t *testing.T, service *UserService, userID uint, want ...string) {
+	t.Helper()
+
+	user, err := service.GetUserByID(userID)
+	if err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	got := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		got = append(got, role.Code)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("用户角色为 %v，期望 %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("用户角色为 %v，期望 %v", got, want)
+		}
+	}
+}
+
+// assertPermission 校验用户是否拥有权限
+func assertPermission(t *testing.T, service *UserService, userID uint, code string, want bool) {
+	t.Helper()
+
+	got, err := service.HasPermission(userID, code)
+	if err != nil {
+		t.Fatalf("查询权限失败: %v", err)
+	}
+	if got != want {
+		t.Fatalf("用户拥有权限 %s 为 %v，期望 %v", code, got, want)
+	}
+}
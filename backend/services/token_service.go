@@ -0,0 +1,274 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// TokenService 令牌服务
+type TokenService struct {
+	Clock utils.Clock // 为空时使用系统时钟
+}
+
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"` // 访问令牌有效期（秒）
+
+	MustChangePassword bool `json:"mustChangePassword"` // 需修改密码后才能访问认证相关接口以外的接口
+}
+
+var (
+	// ErrRefreshTokenInvalid 刷新令牌无效或已过期
+	ErrRefreshTokenInvalid = unauthorized("refresh_token_invalid")
+	// ErrRefreshTokenReused 刷新令牌被重复使用
+	ErrRefreshTokenReused = unauthorized("refresh_token_reused")
+	// ErrAccessTokenRevoked 访问令牌已被吊销
+	ErrAccessTokenRevoked = unauthorized(utils.ErrKeyTokenInvalid)
+)
+
+func (s *TokenService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock.Now()
+	}
+	return time.Now()
+}
+
+// IssueTokenPair 登录时签发新的令牌对，并开启新的令牌族
+func (s *TokenService) IssueTokenPair(user *models.User) (*TokenPair, error) {
+	familyID, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *TokenPair
+	err = models.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pair, _, err = issueTokenPair(tx, user, familyID, s.now())
+		return err
+	})
+	return pair, err
+}
+
+// Refresh 使用刷新令牌换取新的令牌对；旧令牌立即失效，重复使用将吊销整个令牌族
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	var pair *TokenPair
+	var reused bool
+	now := s.now()
+
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&stored).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if stored.RevokedAt != nil {
+			reused = stored.ReplacedBy != nil
+			if reused {
+				return ErrRefreshTokenReused
+			}
+			return ErrRefreshTokenInvalid
+		}
+		if now.After(stored.ExpiresAt) {
+			return ErrRefreshTokenInvalid
+		}
+
+		var user models.User
+		if err := tx.First(&user, stored.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if user.Status != 1 {
+			return ErrRefreshTokenInvalid
+		}
+		// 密码超过最长使用期限时要求修改密码，持续刷新令牌也不能绕过
+		if !user.MustChangePassword && passwordExpired(&user, now) {
+			if err := tx.Model(&user).Update("must_change_password", true).Error; err != nil {
+				return err
+			}
+			user.MustChangePassword = true
+		}
+
+		var newID uint
+		var err error
+		pair, newID, err = issueTokenPair(tx, &user, stored.FamilyID, now)
+		if err != nil {
+			return err
+		}
+
+		// 条件更新防止并发刷新时同一令牌被使用两次
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Updates(map[string]interface{}{"revoked_at": now, "replaced_by": newID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+		return nil
+	})
+
+	// 检测到重放时在事务外吊销整个令牌族，避免被上面的回滚撤销
+	if reused {
+		s.revokeFamilyByToken(refreshToken)
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RevokeRefreshToken 吊销刷新令牌所属的令牌族，用于登出
+func (s *TokenService) RevokeRefreshToken(refreshToken string) {
+	s.revokeFamilyByToken(refreshToken)
+}
+
+// RevokeAccessToken 将访问令牌加入吊销列表
+func (s *TokenService) RevokeAccessToken(claims *utils.Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	expiresAt := s.now().Add(config.GetAccessTokenExpire())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	revoked := models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: expiresAt,
+	}
+	return models.DB.Where(models.RevokedToken{JTI: claims.ID}).FirstOrCreate(&revoked).Error
+}
+
+// RevokeUserSessions 注销用户的全部会话：递增令牌版本并吊销所有刷新令牌
+func (s *TokenService) RevokeUserSessions(userID uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		return revokeUserSessions(tx, userID, s.now())
+	})
+}
+
+// ValidateAccessToken 校验访问令牌未被吊销且令牌版本与用户一致，返回令牌对应的用户
+func (s *TokenService) ValidateAccessToken(claims *utils.Claims) (*models.User, error) {
+	var count int64
+	if err := models.DB.Model(&models.RevokedToken{}).Where("jti = ?", claims.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAccessTokenRevoked
+	}
+
+	// 用户被删除、禁用或令牌版本变更时令牌失效
+	var user models.User
+	if err := models.DB.Select("id", "status", "token_version", "must_change_password", "password_changed_at", "mfa_enabled", "locale").First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessTokenRevoked
+		}
+		return nil, err
+	}
+	if user.Status != 1 || user.TokenVersion != claims.TokenVersion {
+		return nil, ErrAccessTokenRevoked
+	}
+	// 访问令牌有效期内密码过期时同样要求先修改密码
+	if passwordExpired(&user, s.now()) {
+		user.MustChangePassword = true
+	}
+	return &user, nil
+}
+
+// CleanupExpired 清理已过期的吊销记录、刷新令牌、密码重置令牌和两步验证挑战
+func (s *TokenService) CleanupExpired() error {
+	now := s.now()
+	if err := models.DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	if err := models.DB.Where("expires_at < ?", now).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := models.DB.Where("expires_at < ?", now).Delete(&models.PasswordResetToken{}).Error; err != nil {
+		return err
+	}
+	return models.DB.Where("expires_at < ?", now).Delete(&models.MFAChallenge{}).Error
+}
+
+// StartCleanup 在后台定期清理过期令牌
+func (s *TokenService) StartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.CleanupExpired(); err != nil {
+				log.Println("清理过期令牌失败:", err)
+			}
+		}
+	}()
+}
+
+// revokeUserSessions 在事务中递增令牌版本并吊销刷新令牌，now 为吊销时间
+func revokeUserSessions(tx *gorm.DB, userID uint, now time.Time) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// revokeFamilyByToken 吊销令牌所属的整个令牌族
+func (s *TokenService) revokeFamilyByToken(refreshToken string) {
+	var stored models.RefreshToken
+	if err := models.DB.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&stored).Error; err != nil {
+		return
+	}
+	models.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", stored.FamilyID).
+		Update("revoked_at", s.now())
+}
+
+// issueTokenPair 在事务中签发访问令牌并保存刷新令牌，now 为签发时间
+func issueTokenPair(tx *gorm.DB, user *models.User, familyID string, now time.Time) (*TokenPair, uint, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Username, user.TokenVersion, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stored := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(config.GetRefreshTokenExpire()),
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &TokenPair{
+		Token:              accessToken,
+		RefreshToken:       refreshToken,
+		ExpiresIn:          int64(config.GetAccessTokenExpire().Seconds()),
+		MustChangePassword: user.MustChangePassword,
+	}, stored.ID, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"react-go-admin-backend/config"
+	"react-go-admin-backend/utils"
+)
+
+func TestTokenServiceRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance time.Duration
+		reuse   bool
+		want    error
+	}{
+		{name: "有效期内刷新", advance: 7*24*time.Hour - time.Second},
+		{name: "刷新令牌过期", advance: 7*24*time.Hour + time.Second, want: ErrRefreshTokenInvalid},
+		{name: "重复使用刷新令牌", reuse: true, want: ErrRefreshTokenReused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			user := createTestUser(t, clock, "alice", testPassword)
+			service := &TokenService{Clock: clock}
+
+			pair, err := service.IssueTokenPair(user)
+			if err != nil {
+				t.Fatalf("签发令牌失败: %v", err)
+			}
+			clock.Advance(tt.advance)
+
+			token := pair.RefreshToken
+			if tt.reuse {
+				if _, err := service.Refresh(token); err != nil {
+					t.Fatalf("首次刷新失败: %v", err)
+				}
+			}
+			next, err := service.Refresh(token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("刷新错误为 %v，期望 %v", err, tt.want)
+			}
+			if tt.want == nil && next.RefreshToken == token {
+				t.Fatal("刷新后应返回新的刷新令牌")
+			}
+		})
+	}
+}
+
+func TestTokenServiceReuseRevokesFamily(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	user := createTestUser(t, clock, "alice", testPassword)
+	service := &TokenService{Clock: clock}
+
+	pair, err := service.IssueTokenPair(user)
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	next, err := service.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+
+	if _, err := service.Refresh(pair.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("重复使用旧令牌的错误为 %v，期望 %v", err, ErrRefreshTokenReused)
+	}
+	// 检测到重放后同一令牌族的新令牌也失效
+	if _, err := service.Refresh(next.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("令牌族被吊销后刷新的错误为 %v，期望 %v", err, ErrRefreshTokenInvalid)
+	}
+}
+
+func TestTokenServiceRefreshPasswordExpiry(t *testing.T) {
+	setupTestDB(t)
+	setSecurity(t, func(security *config.SecurityConfig) {
+		security.Password.MaxAgeDay = 30
+	})
+	clock := newFakeClock()
+	user := createTestUser(t, clock, "alice", testPassword)
+	bob := createTestUser(t, clock, "bob", testPassword)
+	service := &TokenService{Clock: clock}
+
+	pair, err := service.IssueTokenPair(user)
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	// 每 6 天刷新一次，刷新令牌始终有效，密码在第 30 天后过期
+	for day := 6; day <= 36; day += 6 {
+		clock.Advance(6 * 24 * time.Hour)
+		if pair, err = service.Refresh(pair.RefreshToken); err != nil {
+			t.Fatalf("第 %d 天刷新失败: %v", day, err)
+		}
+		if want := day > 30; pair.MustChangePassword != want {
+			t.Fatalf("第 %d 天刷新后 mustChangePassword 为 %v，期望 %v", day, pair.MustChangePassword, want)
+		}
+	}
+	if !reloadUser(t, "alice").MustChangePassword {
+		t.Error("密码过期后刷新令牌应标记用户需修改密码")
+	}
+
+	// 未刷新过令牌的用户，访问令牌校验时同样按密码期限要求修改密码
+	validated, err := service.ValidateAccessToken(&utils.Claims{UserID: bob.ID, TokenVersion: bob.TokenVersion})
+	if err != nil {
+		t.Fatalf("校验访问令牌失败: %v", err)
+	}
+	if !validated.MustChangePassword {
+		t.Error("密码过期后访问令牌应要求修改密码")
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+
+	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// BatchResult 批量操作中单个用户的处理结果
+type BatchResult struct {
+	ID       uint   `json:"id"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	ErrorKey string `json:"errorKey,omitempty"` // 错误键，与响应中的 error 字段一致
+	Err      error  `json:"-"`                  // 失败原因，由 api 层转换为 Error 及 ErrorKey
+	Password string `json:"password,omitempty"` // 重置密码时返回的临时密码
+}
+
+// 批量角色分配模式
+const (
+	BatchRoleReplace = "replace"
+	BatchRoleAdd     = "add"
+	BatchRoleRemove  = "remove"
+)
+
+// runBatch 在同一事务中逐个处理用户；单个用户失败只回滚到其保存点并记录原因
+func (s *UserService) runBatch(operatorID uint, ids []uint, fn func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error)) ([]BatchResult, error) {
+	results := make([]BatchResult, 0, len(ids))
+
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		guard, err := newAdminGuard(tx)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[uint]bool, len(ids))
+		for i, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			result := BatchResult{ID: id}
+			if id == operatorID {
+				result.Err = ErrOperateSelf
+				results = append(results, result)
+				continue
+			}
+
+			savePoint := fmt.Sprintf("batch_%d", i)
+			if err := tx.SavePoint(savePoint).Error; err != nil {
+				return err
+			}
+
+			password, err := func() (string, error) {
+				user, err := findUser(tx, id)
+				if err != nil {
+					return "", err
+				}
+				return fn(tx, user, guard)
+			}()
+			if err != nil {
+				if rbErr := tx.RollbackTo(savePoint).Error; rbErr != nil {
+					return rbErr
+				}
+				result.Err = err
+			} else {
+				result.Success = true
+				result.Password = password
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// BatchDelete 批量删除用户（移入回收站）
+func (s *UserService) BatchDelete(operatorID uint, ids []uint) ([]BatchResult, error) {
+	return s.runBatch(operatorID, ids, func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error) {
+		if err := guard.remove(user.ID); err != nil {
+			return "", err
+		}
+		if err := revokeUserSessions(tx, user.ID, s.now()); err != nil {
+			return "", err
+		}
+		return "", tx.Delete(user).Error
+	})
+}
+
+// BatchUpdateStatus 批量启用或禁用用户
+func (s *UserService) BatchUpdateStatus(operatorID uint, ids []uint, status int) ([]BatchResult, error) {
+	return s.runBatch(operatorID, ids, func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error) {
+		if user.Status == status {
+			return "", nil
+		}
+		if status != 1 {
+			if err := guard.remove(user.ID); err != nil {
+				return "", err
+			}
+		}
+		if err := tx.Model(user).Update("status", status).Error; err != nil {
+			return "", err
+		}
+		return "", revokeUserSessions(tx, user.ID, s.now())
+	})
+}
+
+// BatchAssignRoles 批量分配角色，mode 为 replace、add 或 remove
+func (s *UserService) BatchAssignRoles(operatorID uint, ids []uint, roleIDs []uint, mode string) ([]BatchResult, error) {
+	roles, err := findRoles(models.DB, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.runBatch(operatorID, ids, func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error) {
+		association := tx.Model(user).Association("Roles")
+
+		switch mode {
+		case BatchRoleAdd:
+			if len(roles) == 0 {
+				return "", nil
+			}
+			return "", association.Append(roles)
+		case BatchRoleRemove:
+			if containsRole(roles, guard.roleID) {
+				if err := guard.remove(user.ID); err != nil {
+					return "", err
+				}
+			}
+			if len(roles) == 0 {
+				return "", nil
+			}
+			return "", association.Delete(roles)
+		default:
+			if !containsRole(roles, guard.roleID) {
+				if err := guard.remove(user.ID); err != nil {
+					return "", err
+				}
+			}
+			return "", replaceUserRoles(tx, user, roles)
+		}
+	})
+}
+
+// BatchResetPassword 批量重置密码；password 为空时为每个用户生成临时密码，重置后需在下次登录时修改
+func (s *UserService) BatchResetPassword(operatorID uint, ids []uint, password string) ([]BatchResult, error) {
+	// 指定的密码先按通用规则校验，与用户名及历史密码相关的规则在为每个用户重置时校验
+	if password != "" {
+		if err := validatePassword(models.DB, "password", password, nil, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.runBatch(operatorID, ids, func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error) {
+		newPassword, err := resetPassword(tx, user, password, s.now())
+		if err != nil {
+			return "", err
+		}
+
+		// 仅返回系统生成的临时密码
+		if password != "" {
+			return "", nil
+		}
+		return newPassword, nil
+	})
+}
+
+// containsRole 判断角色列表是否包含指定角色
+func containsRole(roles []models.Role, roleID uint) bool {
+	for _, role := range roles {
+		if role.ID == roleID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"react-go-admin-backend/models"
+
+	"gorm.io/gorm"
+)
+
+func TestUserServiceBatch(t *testing.T) {
+	// 固定的用户：root 与 alice 为管理员，bob 与 carol 为普通用户
+	type users struct {
+		root, alice, bob, carol *models.User
+		adminRole               models.Role
+	}
+
+	tests := []struct {
+		name string
+		run  func(service *UserService, u users) ([]BatchResult, error)
+		// want 每个 ID 期望的错误，nil 表示成功
+		want func(u users) map[uint]error
+		// check 校验批量操作后的数据
+		check func(t *testing.T, u users)
+	}{
+		{
+			name: "批量删除包含当前用户",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.BatchDelete(u.root.ID, []uint{u.root.ID, u.bob.ID})
+			},
+			want: func(u users) map[uint]error { return map[uint]error{u.root.ID: ErrOperateSelf, u.bob.ID: nil} },
+			check: func(t *testing.T, u users) {
+				assertUserExists(t, u.root.ID, true)
+				assertUserExists(t, u.bob.ID, false)
+			},
+		},
+		{
+			name: "批量禁用全部管理员",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.BatchUpdateStatus(u.bob.ID, []uint{u.root.ID, u.alice.ID}, 0)
+			},
+			want: func(u users) map[uint]error { return map[uint]error{u.root.ID: nil, u.alice.ID: ErrLastAdmin} },
+			check: func(t *testing.T, u users) {
+				if reloadUser(t, "alice").Status != 1 {
+					t.Error("最后一个管理员不应被禁用")
+				}
+			},
+		},
+		{
+			name: "批量删除全部管理员",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.BatchDelete(u.bob.ID, []uint{u.alice.ID, u.root.ID})
+			},
+			want: func(u users) map[uint]error { return map[uint]error{u.alice.ID: nil, u.root.ID: ErrLastAdmin} },
+			check: func(t *testing.T, u users) {
+				assertUserExists(t, u.alice.ID, false)
+				assertUserExists(t, u.root.ID, true)
+			},
+		},
+		{
+			name: "批量移除全部管理员的管理员角色",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.BatchAssignRoles(u.bob.ID, []uint{u.root.ID, u.alice.ID}, []uint{u.adminRole.ID}, BatchRoleRemove)
+			},
+			want: func(u users) map[uint]error { return map[uint]error{u.root.ID: nil, u.alice.ID: ErrLastAdmin} },
+			check: func(t *testing.T, u users) {
+				service := &UserService{}
+				assertUserRoles(t, service, u.root.ID)
+				assertUserRoles(t, service, u.alice.ID, "admin")
+			},
+		},
+		{
+			name: "部分用户失败时其余用户照常提交",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.BatchDelete(u.root.ID, []uint{u.bob.ID, 9999, u.carol.ID})
+			},
+			want: func(u users) map[uint]error {
+				return map[uint]error{u.bob.ID: nil, 9999: ErrUserNotFound, u.carol.ID: nil}
+			},
+			check: func(t *testing.T, u users) {
+				assertUserExists(t, u.bob.ID, false)
+				assertUserExists(t, u.carol.ID, false)
+			},
+		},
+		{
+			name: "单个用户失败时回滚其已写入的修改",
+			run: func(service *UserService, u users) ([]BatchResult, error) {
+				return service.runBatch(u.root.ID, []uint{u.bob.ID, u.carol.ID}, func(tx *gorm.DB, user *models.User, guard *adminGuard) (string, error) {
+					if err := tx.Model(user).Update("realname", "已修改").Error; err != nil {
+						return "", err
+					}
+					if user.ID == u.carol.ID {
+						return "", ErrUserNotFound
+					}
+					return "", nil
+				})
+			},
+			want: func(u users) map[uint]error { return map[uint]error{u.bob.ID: nil, u.carol.ID: ErrUserNotFound} },
+			check: func(t *testing.T, u users) {
+				if reloadUser(t, "bob").Realname != "已修改" {
+					t.Error("处理成功的用户的修改未提交")
+				}
+				if reloadUser(t, "carol").Realname != "" {
+					t.Error("处理失败的用户的修改未回滚")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			clock := newFakeClock()
+			service := &UserService{Clock: clock}
+
+			u := users{adminRole: models.Role{Name: "超级管理员", Code: "admin", Status: 1}}
+			if err := models.DB.Create(&u.adminRole).Error; err != nil {
+				t.Fatalf("创建角色失败: %v", err)
+			}
+			u.root = createTestUser(t, clock, "root", testPassword)
+			u.alice = createTestUser(t, clock, "alice", testPassword)
+			u.bob = createTestUser(t, clock, "bob", testPassword)
+			u.carol = createTestUser(t, clock, "carol", testPassword)
+			for _, admin := range []*models.User{u.root, u.alice} {
+				if err := service.AddRoles(admin.ID, []uint{u.adminRole.ID}); err != nil {
+					t.Fatalf("分配角色失败: %v", err)
+				}
+			}
+
+			results, err := tt.run(service, u)
+			if err != nil {
+				t.Fatalf("批量操作失败: %v", err)
+			}
+			want := tt.want(u)
+			if len(results) != len(want) {
+				t.Fatalf("返回 %d 条结果，期望 %d 条", len(results), len(want))
+			}
+			for _, result := range results {
+				expected, ok := want[result.ID]
+				if !ok {
+					t.Fatalf("意外的结果 %+v", result)
+				}
+				if expected == nil {
+					if !result.Success || result.Err != nil {
+						t.Errorf("用户 %d 处理失败: %v", result.ID, result.Err)
+					}
+				} else if result.Success || !errors.Is(result.Err, expected) {
+					t.Errorf("用户 %d 的错误为 %v，期望 %v", result.ID, result.Err, expected)
+				}
+			}
+			tt.check(t, u)
+		})
+	}
+}
+
+// assertUserExists 校验用户是否存在（未被移入回收站）
+func assertUserExists(t *testing.T, id uint, want bool) {
+	t.Helper()
+
+	var count int64
+	if err := models.DB.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	if (count > 0) != want {
+		t.Errorf("用户 %d 存在为 %v，期望 %v", id, count > 0, want)
+	}
+}
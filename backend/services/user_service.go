@@ -2,27 +2,78 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
 	"react-go-admin-backend/models"
+	"react-go-admin-backend/utils"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // UserService 用户服务
-type UserService struct{}
+type UserService struct {
+	Clock utils.Clock // 为空时使用系统时钟
+}
+
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = notFound("user_not_found")
+	// ErrDeletedUserNotFound 回收站中不存在该用户
+	ErrDeletedUserNotFound = notFound("deleted_user_not_found")
+	// ErrUsernameExists 用户名已存在
+	ErrUsernameExists = conflict("username_exists")
+	// ErrUsernameDeleted 用户名被回收站中的用户占用
+	ErrUsernameDeleted = conflict("username_deleted")
+	// ErrResetOwnPassword 管理员重置自己的密码
+	ErrResetOwnPassword = forbidden("reset_own_password")
+)
 
-// GetUserList 获取用户列表
-func (s *UserService) GetUserList(page, pageSize int) ([]models.User, int64, error) {
+// UserListQuery 用户列表查询参数
+type UserListQuery struct {
+	ListQuery
+	RoleID uint
+}
+
+// userSortable 用户列表可排序字段
+var userSortable = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"realname":   "realname",
+	"email":      "email",
+	"status":     "status",
+	"createdAt":  "created_at",
+	"created_at": "created_at",
+	"updatedAt":  "updated_at",
+	"updated_at": "updated_at",
+}
+
+func (s *UserService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock.Now()
+	}
+	return time.Now()
+}
+
+// GetUserList 获取用户列表，支持关键词、状态、角色、创建时间过滤及排序
+func (s *UserService) GetUserList(query UserListQuery) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
-	offset := (page - 1) * pageSize
+	query.Normalize()
+
+	db := query.applyFilters(models.DB.Model(&models.User{}), "users", "username", "realname", "email", "phone")
+	if query.RoleID != 0 {
+		db = db.Where("users.id IN (?)", models.DB.Table("user_roles").Select("user_id").Where("role_id = ?", query.RoleID))
+	}
 
-	if err := models.DB.Model(&models.User{}).Count(&total).Error; err != nil {
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := models.DB.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := query.applyOrder(db, "users", userSortable).Preload("Roles").
+		Offset(query.Offset()).Limit(query.PageSize).Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -32,9 +83,9 @@ func (s *UserService) GetUserList(page, pageSize int) ([]models.User, int64, err
 // GetUserByID 根据 ID 获取用户
 func (s *UserService) GetUserByID(id uint) (*models.User, error) {
 	var user models.User
-	if err := models.DB.First(&user, id).Error; err != nil {
+	if err := models.DB.Preload("Roles").First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -46,7 +97,7 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 	var user models.User
 	if err := models.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -55,11 +106,12 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 
 // CreateUser 创建用户
 func (s *UserService) CreateUser(user *models.User) error {
-	// 检查用户名是否已存在
-	var count int64
-	models.DB.Model(&models.User{}).Where("username = ?", user.Username).Count(&count)
-	if count > 0 {
-		return errors.New("用户名已存在")
+	if err := checkUsername(models.DB, user.Username, 0); err != nil {
+		return err
+	}
+
+	if err := validatePassword(models.DB, "password", user.Password, nil, user.Username); err != nil {
+		return err
 	}
 
 	// 加密密码
@@ -68,27 +120,265 @@ func (s *UserService) CreateUser(user *models.User) error {
 		return err
 	}
 	user.Password = string(hashedPassword)
+	now := s.now()
+	user.PasswordChangedAt = &now
+
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		roles := user.Roles
+		user.Roles = nil
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(roles))
+		for _, role := range roles {
+			ids = append(ids, role.ID)
+		}
+		found, err := findRoles(tx, ids)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(user).Association("Roles").Replace(found); err != nil {
+			return err
+		}
+		user.Roles = found
+		return nil
+	})
+}
+
+// UpdateUser 更新用户，更新密码时按密码策略校验；不能禁用自己或最后一个管理员
+func (s *UserService) UpdateUser(operatorID, id uint, updates map[string]interface{}) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		return s.updateUser(tx, operatorID, id, updates, "password")
+	})
+}
+
+// UpdateUserWithRoles 在同一事务中更新用户资料及角色集合，任一步失败时全部回滚；roleIDs 为 nil 时不修改角色
+func (s *UserService) UpdateUserWithRoles(operatorID, id uint, updates map[string]interface{}, roleIDs []uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.updateUser(tx, operatorID, id, updates, "password"); err != nil {
+			return err
+		}
+		if roleIDs == nil {
+			return nil
+		}
+		return assignRoles(tx, operatorID, id, roleIDs)
+	})
+}
+
+// ChangePassword 用户修改自己的密码，新密码需符合密码策略，修改后清除强制改密标记
+func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if !s.VerifyPassword(user, oldPassword) {
+		return invalid("oldPassword", "mismatch", "old_password_mismatch")
+	}
+
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		return s.updateUser(tx, id, id, map[string]interface{}{
+			"password":             newPassword,
+			"must_change_password": false,
+		}, "newPassword")
+	})
+}
+
+// ResetPassword 管理员将用户密码重置为系统生成的临时密码，用户下次登录时必须修改
+func (s *UserService) ResetPassword(operatorID, id uint) (string, error) {
+	if operatorID == id {
+		return "", ErrResetOwnPassword
+	}
+
+	var password string
+	err := models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, id)
+		if err != nil {
+			return err
+		}
+		password, err = resetPassword(tx, user, "", s.now())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return password, nil
+}
 
-	return models.DB.Create(user).Error
+// resetPassword 将用户密码重置为指定密码（为空时生成临时密码），要求下次登录修改密码并注销已有会话；
+// 指定的密码与其他修改密码的途径一样按密码策略、用户名及历史密码校验
+func resetPassword(tx *gorm.DB, user *models.User, password string, now time.Time) (string, error) {
+	if password == "" {
+		var err error
+		if password, err = generateTempPassword(); err != nil {
+			return "", err
+		}
+	} else if err := validatePassword(tx, "password", password, user, user.Username); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"password":             string(hashedPassword),
+		"must_change_password": true,
+		"password_changed_at":  now,
+	}).Error; err != nil {
+		return "", err
+	}
+	if err := recordPasswordHistory(tx, user.ID, string(hashedPassword)); err != nil {
+		return "", err
+	}
+	if err := revokeUserSessions(tx, user.ID, now); err != nil {
+		return "", err
+	}
+	return password, nil
 }
 
-// UpdateUser 更新用户
-func (s *UserService) UpdateUser(id uint, updates map[string]interface{}) error {
-	// 如果更新密码，需要加密
+// updateUser 在事务中更新用户，passwordField 为密码校验失败时返回的字段名
+func (s *UserService) updateUser(tx *gorm.DB, operatorID, id uint, updates map[string]interface{}, passwordField string) error {
+	user, err := findUser(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if status, ok := updates["status"].(int); ok && status != 1 && user.Status == 1 {
+		if err := guardUserRemoval(tx, operatorID, id); err != nil {
+			return err
+		}
+	}
+
+	if username, ok := updates["username"].(string); ok && username != user.Username {
+		if err := checkUsername(tx, username, id); err != nil {
+			return err
+		}
+	}
+
+	// 如果更新密码，需要校验并加密
 	if password, ok := updates["password"].(string); ok && password != "" {
+		if err := validatePassword(tx, passwordField, password, user, user.Username); err != nil {
+			return err
+		}
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 		if err != nil {
 			return err
 		}
 		updates["password"] = string(hashedPassword)
+		updates["password_changed_at"] = s.now()
+		if err := recordPasswordHistory(tx, id, string(hashedPassword)); err != nil {
+			return err
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// 修改密码或变更状态后，已签发的令牌全部失效
+	_, passwordChanged := updates["password"]
+	status, statusSet := updates["status"].(int)
+	if passwordChanged || (statusSet && status != user.Status) {
+		return revokeUserSessions(tx, id, s.now())
+	}
+	return nil
+}
+
+// DeleteUser 删除用户（移入回收站），不能删除自己或最后一个管理员
+func (s *UserService) DeleteUser(operatorID, id uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		if err := guardUserRemoval(tx, operatorID, id); err != nil {
+			return err
+		}
+		result := tx.Delete(&models.User{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return revokeUserSessions(tx, id, s.now())
+	})
+}
+
+// GetDeletedUserList 获取回收站中的用户
+func (s *UserService) GetDeletedUserList(query ListQuery) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query.Normalize()
+
+	db := query.applyFilters(models.DB.Unscoped().Model(&models.User{}).Where("users.deleted_at IS NOT NULL"),
+		"users", "username", "realname", "email", "phone")
+
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	return models.DB.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+	if err := db.Preload("Roles").Order("users.deleted_at DESC").
+		Offset(query.Offset()).Limit(query.PageSize).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
 }
 
-// DeleteUser 删除用户
-func (s *UserService) DeleteUser(id uint) error {
-	return models.DB.Delete(&models.User{}, id).Error
+// RestoreUser 从回收站恢复用户
+func (s *UserService) RestoreUser(id uint) error {
+	result := models.DB.Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeletedUserNotFound
+	}
+	return nil
+}
+
+// userOwnedModels 彻底删除用户时一并删除的用户数据；审计日志作为操作记录保留
+var userOwnedModels = []interface{}{
+	&models.RefreshToken{},
+	&models.RevokedToken{},
+	&models.PasswordHistory{},
+	&models.PasswordResetToken{},
+	&models.LoginLog{},
+	&models.MFARecoveryCode{},
+	&models.MFAChallenge{},
+}
+
+// PurgeUser 彻底删除回收站中的用户及其关联数据
+func (s *UserService) PurgeUser(id uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeletedUserNotFound
+			}
+			return err
+		}
+
+		if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		for _, model := range userOwnedModels {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&user).Error
+	})
 }
 
 // VerifyPassword 验证密码
@@ -96,3 +386,179 @@ func (s *UserService) VerifyPassword(user *models.User, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	return err == nil
 }
+
+// GetUserPermissionCodes 获取用户所有角色拥有的权限代码
+func (s *UserService) GetUserPermissionCodes(userID uint) ([]string, error) {
+	permissions, err := (&PermissionService{}).GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		codes = append(codes, permission.Code)
+	}
+	return codes, nil
+}
+
+// HasPermission 判断用户是否拥有指定权限
+func (s *UserService) HasPermission(userID uint, code string) (bool, error) {
+	codes, err := s.GetUserPermissionCodes(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AssignRoles 替换用户的角色集合，不能移除自己或最后一个管理员的管理员角色
+func (s *UserService) AssignRoles(operatorID, userID uint, roleIDs []uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		return assignRoles(tx, operatorID, userID, roleIDs)
+	})
+}
+
+// AddRoles 为用户追加角色
+func (s *UserService) AddRoles(userID uint, roleIDs []uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		roles, err := findRoles(tx, roleIDs)
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		return tx.Model(user).Association("Roles").Append(roles)
+	})
+}
+
+// RemoveRole 移除用户的角色，不能移除自己或最后一个管理员的管理员角色
+func (s *UserService) RemoveRole(operatorID, userID, roleID uint) error {
+	return models.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&models.Role{}, roleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+
+		guard, err := newAdminGuard(tx)
+		if err != nil {
+			return err
+		}
+		if roleID == guard.roleID {
+			if err := guard.removeAdminRole(operatorID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(user).Association("Roles").Delete(&models.Role{ID: roleID})
+	})
+}
+
+// assignRoles 在事务中替换用户的角色集合，不能移除自己或最后一个管理员的管理员角色
+func assignRoles(tx *gorm.DB, operatorID, userID uint, roleIDs []uint) error {
+	user, err := findUser(tx, userID)
+	if err != nil {
+		return err
+	}
+	roles, err := findRoles(tx, roleIDs)
+	if err != nil {
+		return err
+	}
+
+	guard, err := newAdminGuard(tx)
+	if err != nil {
+		return err
+	}
+	if !containsRole(roles, guard.roleID) {
+		if err := guard.removeAdminRole(operatorID, userID); err != nil {
+			return err
+		}
+	}
+	return replaceUserRoles(tx, user, roles)
+}
+
+// replaceUserRoles 替换用户的有效角色；回收站中角色的关联予以保留，角色恢复后继续生效
+func replaceUserRoles(tx *gorm.DB, user *models.User, roles []models.Role) error {
+	query := "DELETE FROM user_roles WHERE user_id = ? AND role_id IN (SELECT id FROM roles WHERE deleted_at IS NULL)"
+	args := []interface{}{user.ID}
+	if len(roles) > 0 {
+		ids := make([]uint, 0, len(roles))
+		for _, role := range roles {
+			ids = append(ids, role.ID)
+		}
+		query += " AND role_id NOT IN ?"
+		args = append(args, ids)
+	}
+	if err := tx.Exec(query, args...).Error; err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	return tx.Model(user).Association("Roles").Append(roles)
+}
+
+// checkUsername 检查用户名是否已被其他用户（含回收站中的用户）使用，excludeID 为正在修改的用户
+func checkUsername(tx *gorm.DB, username string, excludeID uint) error {
+	var existing models.User
+	err := tx.Unscoped().Where("username = ? AND id <> ?", username, excludeID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.DeletedAt.Valid {
+		return ErrUsernameDeleted
+	}
+	return ErrUsernameExists
+}
+
+// findUser 在事务中查询用户
+func findUser(tx *gorm.DB, id uint) (*models.User, error) {
+	var user models.User
+	if err := tx.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// findRoles 按 ID 查询角色，存在未知 ID 时返回错误
+func findRoles(tx *gorm.DB, ids []uint) ([]models.Role, error) {
+	roles := []models.Role{}
+	if len(ids) == 0 {
+		return roles, nil
+	}
+
+	if err := tx.Where("id IN ?", ids).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(roles))
+	for _, role := range roles {
+		found[role.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, invalid("role_ids", "exists", "role_ids_not_found", fmt.Sprint(id))
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"react-go-admin-backend/models"
+)
+
+func TestUserServicePurgeUser(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	service := &UserService{Clock: clock}
+	alice := createTestUser(t, clock, "alice", testPassword)
+	bob := createTestUser(t, clock, "bob", testPassword)
+
+	for _, user := range []*models.User{alice, bob} {
+		// 为每个用户写入一条各类关联数据
+		rows := []interface{}{
+			&models.RefreshToken{UserID: user.ID, TokenHash: user.Username + "-refresh", FamilyID: "family", ExpiresAt: clock.Now()},
+			&models.RevokedToken{JTI: user.Username + "-jti", UserID: user.ID, ExpiresAt: clock.Now()},
+			&models.PasswordResetToken{UserID: user.ID, TokenHash: user.Username + "-reset", ExpiresAt: clock.Now()},
+			&models.LoginLog{UserID: user.ID, Username: user.Username, Reason: models.LoginReasonSuccess},
+			&models.MFARecoveryCode{UserID: user.ID, CodeHash: user.Username + "-code"},
+			&models.MFAChallenge{UserID: user.ID, TokenHash: user.Username + "-challenge", ExpiresAt: clock.Now().Add(time.Minute)},
+		}
+		for _, row := range rows {
+			if err := models.DB.Create(row).Error; err != nil {
+				t.Fatalf("写入 %T 失败: %v", row, err)
+			}
+		}
+	}
+
+	if err := service.DeleteUser(bob.ID, alice.ID); err != nil {
+		t.Fatalf("删除用户失败: %v", err)
+	}
+	if err := service.PurgeUser(alice.ID); err != nil {
+		t.Fatalf("彻底删除用户失败: %v", err)
+	}
+
+	for _, model := range userOwnedModels {
+		var remaining, others int64
+		if err := models.DB.Model(model).Where("user_id = ?", alice.ID).Count(&remaining).Error; err != nil {
+			t.Fatalf("查询 %T 失败: %v", model, err)
+		}
+		if err := models.DB.Model(model).Where("user_id = ?", bob.ID).Count(&others).Error; err != nil {
+			t.Fatalf("查询 %T 失败: %v", model, err)
+		}
+		if remaining != 0 {
+			t.Errorf("%T 仍有 %d 条已删除用户的数据", model, remaining)
+		}
+		if others == 0 {
+			t.Errorf("%T 中其他用户的数据被误删", model)
+		}
+	}
+
+	var count int64
+	if err := models.DB.Unscoped().Model(&models.User{}).Where("id = ?", alice.ID).Count(&count).Error; err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	if count != 0 {
+		t.Error("用户记录未被彻底删除")
+	}
+}
+
+func TestUserServiceUpdateUserWithRoles(t *testing.T) {
+	setupTestDB(t)
+	clock := newFakeClock()
+	service := &UserService{Clock: clock}
+	user := createTestUser(t, clock, "alice", testPassword)
+	role := models.Role{Name: "编辑", Code: "editor", Status: 1}
+	if err := models.DB.Create(&role).Error; err != nil {
+		t.Fatalf("创建角色失败: %v", err)
+	}
+
+	// 角色无效时资料的修改一并回滚
+	err := service.UpdateUserWithRoles(0, user.ID, map[string]interface{}{"realname": "Alice"}, []uint{role.ID, 9999})
+	if err == nil {
+		t.Fatal("角色不存在时应返回错误")
+	}
+	if reloaded := reloadUser(t, "alice"); reloaded.Realname != "" {
+		t.Errorf("更新失败后姓名为 %q，应已回滚", reloaded.Realname)
+	}
+
+	if err := service.UpdateUserWithRoles(0, user.ID, map[string]interface{}{"realname": "Alice"}, []uint{role.ID}); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+	assertUserRoles(t, service, user.ID, "editor")
+
+	// roleIDs 为 nil 时不修改角色
+	if err := service.UpdateUserWithRoles(0, user.ID, map[string]interface{}{"realname": "Alice Liddell"}, nil); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+	assertUserRoles(t, service, user.ID, "editor")
+	if reloaded := reloadUser(t, "alice"); reloaded.Realname != "Alice Liddell" {
+		t.Errorf("姓名为 %q，期望 Alice Liddell", reloaded.Realname)
+	}
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "time"
+
+// Clock 时钟，便于在测试中注入固定时间
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock 系统时钟
+type SystemClock struct{}
+
+// Now 返回当前时间
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,16 @@
+package utils
+
+// 通用错误键，对应响应中的 error 字段，供前端识别错误类型
+const (
+	ErrKeyBadRequest             = "bad_request"
+	ErrKeyValidation             = "validation_failed"
+	ErrKeyUnauthorized           = "unauthorized"
+	ErrKeyTokenMalformed         = "token_malformed"
+	ErrKeyTokenInvalid           = "token_invalid"
+	ErrKeyForbidden              = "forbidden"
+	ErrKeyPasswordChangeRequired = "password_change_required"
+	ErrKeyMFASetupRequired       = "mfa_setup_required"
+	ErrKeyNotFound               = "not_found"
+	ErrKeyConflict               = "conflict"
+	ErrKeyInternal               = "internal_error"
+)
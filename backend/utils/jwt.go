@@ -10,19 +10,27 @@ import (
 
 // Claims JWT 声明
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	TokenVersion int    `json:"ver"` // 用户令牌版本，版本变更后旧令牌失效
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT token
-func GenerateToken(userID uint, username string) (string, error) {
+// GenerateToken 生成 JWT token，now 为签发时间
+func GenerateToken(userID uint, username string, tokenVersion int, now time.Time) (string, error) {
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:       userID,
+		Username:     username,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(config.GetJWTExpireHour()))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(config.GetAccessTokenExpire())),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -2,9 +2,20 @@ package utils
 
 // Response 统一响应结构
 type Response struct {
-	Code int         `json:"code"`
-	Msg  string      `json:"msg"`
-	Data interface{} `json:"data,omitempty"`
+	Code   int          `json:"code"`
+	Msg    string       `json:"msg"`
+	Data   interface{}  `json:"data,omitempty"`
+	Error  string       `json:"error,omitempty"`  // 错误键，见 errcode.go 及各业务错误定义
+	Errors []FieldError `json:"errors,omitempty"` // 字段级错误
+}
+
+// FieldError 字段级错误
+type FieldError struct {
+	Field   string        `json:"field"`
+	Rule    string        `json:"rule"`
+	Message string        `json:"message"`
+	Key     string        `json:"-"` // 消息目录中的键，为空时 Message 不再翻译
+	Args    []interface{} `json:"-"`
 }
 
 // Success 成功响应
@@ -32,6 +43,30 @@ func ErrorWithCode(code int, msg string) Response {
 	}
 }
 
+// Fail 带状态码和错误键的错误响应
+func Fail(code int, key, msg string) Response {
+	return Response{
+		Code:  code,
+		Msg:   msg,
+		Error: key,
+	}
+}
+
+// BadRequest 请求参数错误响应
+func BadRequest(msg string) Response {
+	return Fail(400, ErrKeyBadRequest, msg)
+}
+
+// ErrorWithFields 带字段级错误的参数校验失败响应
+func ErrorWithFields(msg string, errs []FieldError) Response {
+	return Response{
+		Code:   400,
+		Msg:    msg,
+		Error:  ErrKeyValidation,
+		Errors: errs,
+	}
+}
+
 // PageData 分页数据结构
 type PageData struct {
 	List  interface{} `json:"list"`
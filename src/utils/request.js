@@ -159,6 +159,11 @@ request.interceptors.response.use(
              */
             switch (status) {
                 case 401:
+                    // 登录及两步验证接口的 401 表示凭据错误，直接提示服务端返回的消息
+                    if (/^\/auth\/(login|mfa\/verify)$/.test(error.config?.url)) {
+                        message.error(data?.msg || '用户名或密码错误')
+                        break
+                    }
                     // 401 表示未授权，通常是 token 过期或无效
                     message.error('未授权，请重新登录')
                     // 清除本地存储的 token
@@ -169,12 +174,12 @@ request.interceptors.response.use(
 
                 case 403:
                     // 403 表示权限不足，用户没有访问资源的权限
-                    message.error('拒绝访问')
+                    message.error(data?.msg || '拒绝访问')
                     break
 
                 case 404:
                     // 404 表示请求的资源不存在
-                    message.error('请求的资源不存在')
+                    message.error(data?.msg || '请求的资源不存在')
                     break
 
                 case 500: